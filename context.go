@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import "context"
+
+type contextKey int
+
+const loggerContextKey contextKey = 0
+
+// 与链路追踪相关的属性名称
+//
+// [NewTextHandler]、[NewTermHandler] 和 [NewJSONHandler] 会将这些属性作为顶层字段输出，
+// 而不是与其它属性放在一起。
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// ContextExtractor 从 ctx 中提取数据并作为属性添加到 r
+//
+// 一般通过 [Record.With] 向 r 添加属性，比如请求 ID、用户 ID 等。
+type ContextExtractor = func(ctx context.Context, r *Record)
+
+// WithContextExtractors 添加从 [context.Context] 中提取属性的函数
+//
+// 这些函数会在 [Logger.WithContext] 以及 [Logs.SLogHandler] 处理带有 [context.Context] 的日志时调用。
+// 多次调用会依次追加。
+func WithContextExtractors(f ...ContextExtractor) Option {
+	return func(l *Logs) { l.extractors = append(l.extractors, f...) }
+}
+
+// NewContext 返回一个包含 l 的 [context.Context]
+//
+// 可以通过 [FromContext] 从返回值中获取 l。
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext 从 ctx 中获取由 [NewContext] 保存的 [Logger]
+//
+// 如果不存在，则返回 nil。
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerContextKey).(*Logger); ok {
+		return l
+	}
+	return nil
+}
+
+// WithContext 创建从 ctx 中提取属性的 [Recorder] 对象
+//
+// 属性由 [WithContextExtractors] 指定的函数从 ctx 中提取。
+func (l *Logger) WithContext(ctx context.Context) Recorder {
+	if !l.IsEnable() {
+		return disabledRecorder
+	}
+
+	r := withRecordPool.Get().(*withRecorder)
+	r.l = l
+	r.r = l.newRecord().withContext(ctx)
+	return r
+}
+
+func (e *Record) withContext(ctx context.Context) *Record {
+	if ctx != nil {
+		for _, f := range e.logs.extractors {
+			f(ctx, e)
+		}
+	}
+	return e
+}
+
+func isTraceKey(k string) bool { return k == TraceIDKey || k == SpanIDKey || k == TraceFlagsKey }
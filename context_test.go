@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+type requestIDKey struct{}
+
+func requestIDExtractor(ctx context.Context, r *Record) {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		r.With("request_id", id)
+	}
+}
+
+func TestLogger_WithContext(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf), WithContextExtractors(requestIDExtractor))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "id1")
+	l.INFO().WithContext(ctx).With("k1", "v1").String("msg")
+	a.Equal(buf.String(), "[INFO] msg request_id=id1 k1=v1\n")
+
+	buf.Reset()
+	l.INFO().WithContext(context.Background()).String("msg")
+	a.Equal(buf.String(), "[INFO] msg\n")
+
+	l.Enable(LevelError)
+	a.Equal(l.INFO().WithContext(ctx), disabledRecorder)
+
+	// slog
+	buf.Reset()
+	l.Enable(AllLevels()...)
+	slog.New(l.SLogHandler()).InfoContext(ctx, "slog")
+	a.Contains(buf.String(), "slog request_id=id1\n")
+}
+
+func TestNewContext(t *testing.T) {
+	a := assert.New(t, false)
+	l := New(nil)
+
+	a.Nil(FromContext(context.Background()))
+
+	ctx := NewContext(context.Background(), l.ERROR())
+	a.Equal(FromContext(ctx), l.ERROR())
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"sync"
+	"time"
+)
+
+// RepeatedKey [NewDedupHandler] 输出的汇总日志中表示重复次数的属性名称
+const RepeatedKey = "repeated"
+
+type (
+	dedupHandler struct {
+		h Handler
+		d *deduper
+	}
+
+	deduper struct {
+		mux     sync.Mutex
+		window  time.Duration
+		entries map[dedupKey]*dedupEntry
+	}
+
+	dedupKey struct {
+		h   *dedupHandler // 不同的 Handler 带有不同的级别和属性
+		msg string        // 消息及属性
+	}
+
+	dedupEntry struct {
+		r     *Record   // 首条日志的快照
+		count int       // 重复的次数
+		last  time.Time // 最后一条重复日志的时间
+	}
+)
+
+// NewDedupHandler 返回合并重复日志的 [Handler]
+//
+// 级别、消息以及属性都相同的日志被视为重复的日志。
+// 首次出现的日志会直接输出，之后 window 时间内重复出现的日志不再输出，
+// 而是在 window 结束时，以首条日志为基础输出一条汇总日志，其时间为最后一条重复日志的时间，
+// 且带有名为 [RepeatedKey] 的属性，其值为被合并的日志数量。
+//
+// NOTE: 汇总日志是在单独的 goroutine 中输出的，程序退出时尚未输出的汇总日志将会丢失。
+func NewDedupHandler(h Handler, window time.Duration) Handler {
+	return &dedupHandler{
+		h: h,
+		d: &deduper{window: window, entries: make(map[dedupKey]*dedupEntry, 10)},
+	}
+}
+
+func (h *dedupHandler) Handle(e *Record) {
+	b := NewBuffer(false)
+	appendRecordText(b, e)
+	key := dedupKey{h: h, msg: string(b.Bytes())}
+	b.Free()
+
+	h.d.mux.Lock()
+	if entry, found := h.d.entries[key]; found {
+		entry.count++
+		entry.last = e.Time()
+		h.d.mux.Unlock()
+		return
+	}
+	entry := &dedupEntry{}
+	h.d.entries[key] = entry
+	h.d.mux.Unlock()
+
+	h.h.Handle(e)
+
+	// 快照只在首次出现时生成一次，且不占用锁。
+	r := e.snapshot()
+	h.d.mux.Lock()
+	entry.r = r
+	h.d.mux.Unlock()
+	time.AfterFunc(h.d.window, func() { h.flush(key) })
+}
+
+func (h *dedupHandler) flush(key dedupKey) {
+	h.d.mux.Lock()
+	entry := h.d.entries[key]
+	delete(h.d.entries, key)
+	h.d.mux.Unlock()
+
+	if entry.count == 0 {
+		return
+	}
+
+	r := entry.r // 由 snapshot 生成，可以直接修改。
+	if !entry.last.IsZero() {
+		r.created = entry.last
+		if r.AppendCreated != nil && r.logs != nil {
+			t, layout := entry.last, r.logs.createdFormat
+			r.AppendCreated = func(b *Buffer) { b.AppendTime(t, layout) }
+		}
+	}
+	r.Attrs = append(r.Attrs, Attr{K: RepeatedKey, V: entry.count})
+	h.h.Handle(r)
+}
+
+func (h *dedupHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	return &dedupHandler{h: h.h.New(detail, lv, attrs), d: h.d}
+}
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+// 可以在多个 goroutine 中使用的 [bytes.Buffer]
+type syncBuffer struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
+
+func TestDedupHandler(t *testing.T) {
+	a := assert.New(t, false)
+	buf := &syncBuffer{}
+
+	l := New(NewDedupHandler(NewTextHandler(buf), 100*time.Millisecond), WithCreated("15:04:05.000000"))
+	err := errors.New("connection refused")
+	for range 5 {
+		l.ERROR().Error(err)
+		l.ERROR().With("host", "h1").Error(err)
+		l.WARN().Error(err)
+	}
+	l.ERROR().String("other")
+
+	out := buf.String()
+	a.Equal(strings.Count(out, "\n"), 4).
+		Equal(strings.Count(out, "connection refused\n"), 2).
+		Equal(strings.Count(out, "connection refused host=h1\n"), 1).
+		Equal(strings.Count(out, "other\n"), 1)
+
+	time.Sleep(300 * time.Millisecond)
+	out = buf.String()
+	a.Equal(strings.Count(out, "\n"), 7).
+		Equal(strings.Count(out, "[ERRO]"), 5).
+		Equal(strings.Count(out, "[WARN]"), 2).
+		Equal(strings.Count(out, "connection refused repeated=4\n"), 2).
+		Equal(strings.Count(out, "connection refused host=h1 repeated=4\n"), 1)
+
+	// 窗口结束之后重新开始计数
+	l.ERROR().Error(err)
+	a.Equal(strings.Count(buf.String(), "\n"), 8)
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"reflect"
+
+	"github.com/issue9/localeutil"
+)
+
+// 错误树的最大深度，防止 Unwrap 形成环时无限递归。
+const maxErrorTreeDepth = 32
+
+type (
+	// ErrorAttrs 可以为日志提供附加属性的错误
+	//
+	// 在启用了 [WithErrorTree] 之后，错误树中实现了该接口的错误，
+	// 其 LogAttrs 的返回值会与该错误一起输出。
+	ErrorAttrs interface {
+		LogAttrs() []Attr
+	}
+
+	// 错误树中的节点
+	errorNode struct {
+		typ      string
+		msg      string
+		attrs    []Attr
+		children []*errorNode
+	}
+)
+
+// WithErrorTree 是否以树的形式输出 [Recorder.Error] 的错误
+//
+// 启用之后，除了原有的日志消息，还会输出由 Unwrap() error 和 Unwrap() []error
+// 组成的完整错误树，包括由 %w 和 [errors.Join] 生成的错误，
+// 树中的每个错误都会输出其 Go 类型、错误信息以及由 [ErrorAttrs] 提供的属性。
+//
+// [NewTextHandler] 会在日志之后以缩进的多行形式输出错误树，
+// [NewJSONHandler] 则以 error 字段输出嵌套的对象：
+//
+//	{"type":"*fmt.wrapError","message":"open: not found","causes":[{"type":"*errors.errorString","message":"not found"}]}
+func WithErrorTree(v bool) Option { return func(l *Logs) { l.errorTree = v } }
+
+// HasErrorTree 是否以树的形式输出错误
+func (logs *Logs) HasErrorTree() bool { return logs.errorTree }
+
+// 根据 e 生成错误树，未启用 [WithErrorTree] 或是 e 不是由 [Record.DepthError] 输出的则返回 nil。
+func (e *Record) errorTree() *errorNode {
+	if e.err == nil || e.logs == nil || !e.logs.errorTree {
+		return nil
+	}
+	return newErrorNode(e.logs.printer, e.err, 0)
+}
+
+func newErrorNode(p *localeutil.Printer, err error, depth int) *errorNode {
+	n := &errorNode{typ: reflect.TypeOf(err).String()}
+
+	if ls, ok := err.(localeutil.Stringer); ok && p != nil {
+		n.msg = ls.LocaleString(p)
+	} else {
+		n.msg = err.Error()
+	}
+
+	if ea, ok := err.(ErrorAttrs); ok {
+		n.attrs = ea.LogAttrs()
+	}
+
+	if depth >= maxErrorTreeDepth {
+		return n
+	}
+
+	var errs []error
+	switch ue := err.(type) {
+	case interface{ Unwrap() error }:
+		errs = []error{ue.Unwrap()}
+	case interface{ Unwrap() []error }:
+		errs = ue.Unwrap()
+	}
+
+	for _, child := range errs {
+		if child != nil {
+			n.children = append(n.children, newErrorNode(p, child, depth+1))
+		}
+	}
+
+	return n
+}
+
+// 以缩进的多行形式输出错误树
+//
+// 每个错误占一行，以 \t 的数量表示层级，是否转义控制字符由 h 决定。
+func (n *errorNode) appendText(h *textHandler, b *Buffer, indent int) {
+	b.AppendBytes('\n')
+	for range indent {
+		b.AppendBytes('\t')
+	}
+
+	start := b.Len()
+	b.AppendString(n.typ).AppendString(": ").AppendString(n.msg)
+	if !h.raw {
+		sanitize(b, start)
+	}
+	h.buildAttrs(b, n.attrs)
+
+	for _, child := range n.children {
+		child.appendText(h, b, indent+1)
+	}
+}
+
+// 以 {"type":"","message":"","attrs":[],"causes":[]} 的形式输出错误树
+func (n *errorNode) appendJSON(h *jsonHandler, b *Buffer) {
+	b.AppendString(`{"type":`)
+	appendJSONString(b, n.typ)
+	b.AppendString(`,"message":`)
+	appendJSONString(b, n.msg)
+
+	if len(n.attrs) > 0 {
+		b.AppendString(`,"attrs":[`)
+		h.buildAttr(b, n.attrs, false)
+		b.AppendBytes(']')
+	}
+
+	if len(n.children) > 0 {
+		b.AppendString(`,"causes":[`)
+		for i, child := range n.children {
+			if i > 0 {
+				b.AppendBytes(',')
+			}
+			child.appendJSON(h, b)
+		}
+		b.AppendBytes(']')
+	}
+
+	b.AppendBytes('}')
+}
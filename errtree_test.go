@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+type attrsError struct {
+	code int
+}
+
+func (e *attrsError) Error() string { return "attrs\nerror" }
+
+func (e *attrsError) LogAttrs() []Attr { return []Attr{{K: "code", V: e.code}} }
+
+var _ ErrorAttrs = &attrsError{}
+
+func TestWithErrorTree(t *testing.T) {
+	a := assert.New(t, false)
+
+	err := fmt.Errorf("wrap: %w", errors.Join(errors.New("e1"), &attrsError{code: 404}))
+
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf), WithErrorTree(true))
+	a.True(l.HasErrorTree())
+	l.ERROR().With("k", "v").Error(err)
+	l.INFO().String("info") // 非 error 不输出错误树
+	a.Equal(buf.String(), "[ERRO] wrap: e1\\nattrs\\nerror k=v\n"+
+		"\t*fmt.wrapError: wrap: e1\\nattrs\\nerror\n"+
+		"\t\t*errors.joinError: e1\\nattrs\\nerror\n"+
+		"\t\t\t*errors.errorString: e1\n"+
+		"\t\t\t*logs.attrsError: attrs\\nerror code=404\n"+
+		"[INFO] info\n")
+
+	buf.Reset()
+	l = New(NewJSONHandler(buf), WithErrorTree(true))
+	l.ERROR().Error(fmt.Errorf("wrap: %w", &attrsError{code: 500}))
+	a.Equal(buf.String(), `{"level":"ERRO","message":"wrap: attrs`+"\n"+`error",`+
+		`"error":{"type":"*fmt.wrapError","message":"wrap: attrs\nerror","causes":[`+
+		`{"type":"*logs.attrsError","message":"attrs\nerror","attrs":[{"code":500}]}]}}`)
+
+	// 包含引号的错误信息
+	buf.Reset()
+	_, err2 := strconv.Atoi("abc")
+	l.ERROR().Error(fmt.Errorf("wrap: %w", err2))
+	out := buf.String()
+	_, after, found := strings.Cut(out, `,"error":`)
+	a.True(found).
+		True(json.Valid([]byte(after[:len(after)-1])), out).
+		Contains(after, `"message":"strconv.Atoi: parsing \"abc\": invalid syntax"`)
+
+	// 未启用
+	buf.Reset()
+	l = New(NewTextHandler(buf))
+	a.False(l.HasErrorTree())
+	l.ERROR().Error(err)
+	a.Equal(buf.String(), "[ERRO] wrap: e1\\nattrs\\nerror\n")
+}
+
+type multiError []error
+
+func (e multiError) Error() string { return "multi" }
+
+func (e multiError) Unwrap() []error { return e }
+
+type loopError struct{}
+
+func (e *loopError) Error() string { return "loop" }
+
+func (e *loopError) Unwrap() error { return e }
+
+func TestNewErrorNode(t *testing.T) {
+	a := assert.New(t, false)
+
+	n := newErrorNode(nil, &loopError{}, 0)
+	depth := 0
+	for len(n.children) > 0 {
+		n = n.children[0]
+		depth++
+	}
+	a.Equal(depth, maxErrorTreeDepth).Equal(n.typ, "*logs.loopError")
+
+	// Unwrap() []error 中的 nil 会被忽略
+	n = newErrorNode(nil, multiError{nil, errors.New("e1")}, 0)
+	a.Length(n.children, 1).Equal(n.children[0].msg, "e1")
+}
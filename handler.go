@@ -59,6 +59,7 @@ type (
 		attrs  []byte // 预编译的属性值
 		level  []byte // 预处理的 level 内容
 		detail bool
+		raw    bool // 不对控制字符进行转义
 	}
 
 	jsonHandler struct {
@@ -88,13 +89,16 @@ type (
 
 // NewTextHandler 返回将 [Record] 以普通文本的形式写入 w 的对象
 //
-// NOTE: 如果向 w 输出内容时出错，会将错误信息输出到终端作为最后的处理方式。
+// 消息和属性中的控制字符默认会被转义，可以通过 [DisableSanitize] 禁用。
+//
+// NOTE: 如果向 w 输出内容时出错，且未通过 [WithErrorHandler] 指定错误处理函数，
+// 会将错误信息输出到终端作为最后的处理方式。
 func NewTextHandler(w ...io.Writer) Handler {
 	return &textHandler{w: writers.New(w...)}
 }
 
 func (h *textHandler) Handle(e *Record) {
-	if err := h.handle(e); err != nil {
+	if err := h.handle(e); err != nil && !e.HandleError(h, err) {
 		fmt.Fprintf(os.Stderr, "NewTextHandler.Handle:%v\n", err)
 	}
 }
@@ -116,11 +120,33 @@ func (h *textHandler) handle(e *Record) error {
 		indent = '\t'
 	}
 
-	b.AppendBytes(indent).AppendFunc(e.AppendMessage)
+	for _, p := range e.Attrs { // 链路追踪的相关属性放在消息之前
+		if isTraceKey(p.K) {
+			h.buildAttr(b, p)
+			indent = '\t'
+		}
+	}
+
+	b.AppendBytes(indent)
+	if h.raw {
+		b.AppendFunc(e.AppendMessage)
+	} else {
+		appendSanitizedMessage(b, e)
+	}
 
 	b.AppendBytes(h.attrs...)
 
-	h.buildAttrs(b, e.Attrs)
+	for _, p := range e.Attrs {
+		if !isTraceKey(p.K) {
+			h.buildAttr(b, p)
+		}
+	}
+
+	if n := e.errorTree(); n != nil {
+		n.appendText(h, b, 1)
+	}
+
+	appendTextStack(b, e.Stack()) // 堆栈由 runtime 生成，不需要转义。
 
 	b.AppendBytes('\n')
 
@@ -146,48 +172,83 @@ func (h *textHandler) New(detail bool, lv Level, attrs []Attr) Handler {
 		attrs:  append(data, b.Bytes()...),
 		level:  []byte("[" + lv.String() + "]"),
 		detail: detail,
+		raw:    h.raw,
 	}
 }
 
 func (h *textHandler) buildAttrs(b *Buffer, attrs []Attr) {
 	for _, p := range attrs {
-		b.AppendBytes(' ').AppendString(p.K).AppendBytes('=')
-		switch v := p.V.(type) {
-		case string:
-			b.AppendString(v)
-		case int:
-			b.AppendInt(int64(v), 10)
-		case int64:
-			b.AppendInt(v, 10)
-		case int32:
-			b.AppendInt(int64(v), 10)
-		case int16:
-			b.AppendInt(int64(v), 10)
-		case int8:
-			b.AppendInt(int64(v), 10)
-		case uint:
-			b.AppendUint(uint64(v), 10)
-		case uint64:
-			b.AppendUint(v, 10)
-		case uint32:
-			b.AppendUint(uint64(v), 10)
-		case uint16:
-			b.AppendUint(uint64(v), 10)
-		case uint8:
-			b.AppendUint(uint64(v), 10)
-		case float32:
-			b.AppendFloat(float64(v), 'f', -1, 32)
-		case float64:
-			b.AppendFloat(v, 'f', -1, 64)
-		default:
-			b.Append(p.V)
+		h.buildAttr(b, p)
+	}
+}
+
+func (h *textHandler) buildAttr(b *Buffer, p Attr) {
+	if g, ok := p.V.([]Attr); ok { // 分组以 group.key=value 的形式输出
+		for _, pp := range g {
+			h.buildAttr(b, Attr{K: p.K + "." + pp.K, V: pp.V})
 		}
+		return
+	}
+
+	b.AppendBytes(' ')
+	start := b.Len()
+	b.AppendString(p.K).AppendBytes('=')
+	appendTextValue(b, p.V)
+	if !h.raw {
+		sanitize(b, start)
+	}
+}
+
+// 不转义控制字符的 [textHandler]，仅用于调用其 buildAttr 方法。
+var rawTextHandler = &textHandler{raw: true}
+
+// 以 msg k1=v1 k2=v2 的形式向 b 输出 r 的消息和属性，不转义控制字符。
+//
+// 用于计算日志所占的空间或是作为比较日志是否相同的依据。
+func appendRecordText(b *Buffer, r *Record) {
+	b.AppendFunc(r.AppendMessage)
+	for _, p := range r.Attrs {
+		rawTextHandler.buildAttr(b, p)
+	}
+}
+
+func appendTextValue(b *Buffer, val any) {
+	switch v := val.(type) {
+	case string:
+		b.AppendString(v)
+	case int:
+		b.AppendInt(int64(v), 10)
+	case int64:
+		b.AppendInt(v, 10)
+	case int32:
+		b.AppendInt(int64(v), 10)
+	case int16:
+		b.AppendInt(int64(v), 10)
+	case int8:
+		b.AppendInt(int64(v), 10)
+	case uint:
+		b.AppendUint(uint64(v), 10)
+	case uint64:
+		b.AppendUint(v, 10)
+	case uint32:
+		b.AppendUint(uint64(v), 10)
+	case uint16:
+		b.AppendUint(uint64(v), 10)
+	case uint8:
+		b.AppendUint(uint64(v), 10)
+	case float32:
+		b.AppendFloat(float64(v), 'f', -1, 32)
+	case float64:
+		b.AppendFloat(v, 'f', -1, 64)
+	default:
+		b.Append(val)
 	}
 }
 
 // NewJSONHandler 返回将 [Record] 以 JSON 的形式写入 w 的对象
 //
-// NOTE: 如果向 w 输出内容时出错，会将错误信息输出到终端作为最后的处理方式。
+// NOTE: 如果向 w 输出内容时出错，且未通过 [WithErrorHandler] 指定错误处理函数，
+// 会将错误信息输出到终端作为最后的处理方式。
 func NewJSONHandler(w ...io.Writer) Handler {
 	return &jsonHandler{w: writers.New(w...)}
 }
@@ -210,25 +271,46 @@ func (h *jsonHandler) Handle(e *Record) {
 		b.AppendString(`,"path":"`).AppendFunc(e.AppendLocation).AppendBytes('"')
 	}
 
-	if len(e.Attrs) > 0 || len(h.attrs) > 0 {
+	attrs := len(e.Attrs)
+	for _, p := range e.Attrs { // 链路追踪的相关属性作为顶层字段输出
+		if isTraceKey(p.K) {
+			b.AppendString(`,"`).AppendString(p.K).AppendString(`":`)
+			appendJSONValue(b, p.V)
+			attrs--
+		}
+	}
+
+	if attrs > 0 || len(h.attrs) > 0 {
 		b.AppendString(`,"attrs":[`)
 
 		b.AppendBytes(h.attrs...)
 
-		if len(e.Attrs) > 0 && len(h.attrs) > 0 {
+		if attrs > 0 && len(h.attrs) > 0 {
 			b.AppendBytes(',')
 		}
 
-		h.buildAttr(b, e.Attrs)
+		h.buildAttr(b, e.Attrs, true)
 
 		b.AppendBytes(']')
 	}
 
+	if n := e.errorTree(); n != nil {
+		b.AppendString(`,"error":`)
+		n.appendJSON(h, b)
+	}
+
+	if frames := e.Stack(); len(frames) > 0 {
+		b.AppendString(`,"stack":`)
+		appendJSONStack(b, frames)
+	}
+
 	b.AppendBytes('}')
 
 	h.mux.Lock()
-	defer h.mux.Unlock()
-	if _, err := h.w.Write(b.Bytes()); err != nil {
+	_, err := h.w.Write(b.Bytes())
+	h.mux.Unlock()
+
+	if err != nil && !e.HandleError(h, err) {
 		fmt.Fprintf(os.Stderr, "NewJSONHandler.Handle:%v\n", err)
 	}
 }
@@ -237,7 +319,7 @@ func (h *jsonHandler) New(detail bool, lv Level, attrs []Attr) Handler {
 	b := NewBuffer(false)
 	defer b.Free()
 
-	h.buildAttr(b, attrs)
+	h.buildAttr(b, attrs, false)
 	data := make([]byte, 0, b.Len()+len(h.attrs)+1)
 	data = append(data, h.attrs...)
 	if len(h.attrs) > 0 && len(attrs) > 0 {
@@ -253,59 +335,85 @@ func (h *jsonHandler) New(detail bool, lv Level, attrs []Attr) Handler {
 	}
 }
 
-func (h *jsonHandler) buildAttr(b *Buffer, attrs []Attr) {
-	for i, p := range attrs {
-		if i > 0 {
+func (h *jsonHandler) buildAttr(b *Buffer, attrs []Attr, skipTrace bool) {
+	first := true
+	for _, p := range attrs {
+		if skipTrace && isTraceKey(p.K) {
+			continue
+		}
+
+		if !first {
 			b.AppendBytes(',')
 		}
+		first = false
+
 		b.AppendString(`{"`).AppendString(p.K).AppendString(`":`)
+		appendJSONValue(b, p.V)
+		b.AppendBytes('}')
+	}
+}
 
-		switch v := p.V.(type) {
-		case string:
-			b.AppendBytes('"').AppendString(v).AppendBytes('"')
-		case int:
-			b.AppendInt(int64(v), 10)
-		case int64:
-			b.AppendInt(v, 10)
-		case int32:
-			b.AppendInt(int64(v), 10)
-		case int16:
-			b.AppendInt(int64(v), 10)
-		case int8:
-			b.AppendInt(int64(v), 10)
-		case uint:
-			b.AppendUint(uint64(v), 10)
-		case uint64:
-			b.AppendUint(v, 10)
-		case uint32:
-			b.AppendUint(uint64(v), 10)
-		case uint16:
-			b.AppendUint(uint64(v), 10)
-		case uint8:
-			b.AppendUint(uint64(v), 10)
-		case float32:
-			b.AppendFloat(float64(v), 'f', -1, 32)
-		case float64:
-			b.AppendFloat(v, 'f', -1, 64)
-		default:
-			val, err := json.Marshal(p.V)
-			if err != nil {
-				val = []byte(`"Err(` + err.Error() + `)"`)
+func appendJSONValue(b *Buffer, val any) {
+	switch v := val.(type) {
+	case string:
+		b.AppendBytes('"').AppendString(v).AppendBytes('"')
+	case int:
+		b.AppendInt(int64(v), 10)
+	case int64:
+		b.AppendInt(v, 10)
+	case int32:
+		b.AppendInt(int64(v), 10)
+	case int16:
+		b.AppendInt(int64(v), 10)
+	case int8:
+		b.AppendInt(int64(v), 10)
+	case uint:
+		b.AppendUint(uint64(v), 10)
+	case uint64:
+		b.AppendUint(v, 10)
+	case uint32:
+		b.AppendUint(uint64(v), 10)
+	case uint16:
+		b.AppendUint(uint64(v), 10)
+	case uint8:
+		b.AppendUint(uint64(v), 10)
+	case float32:
+		b.AppendFloat(float64(v), 'f', -1, 32)
+	case float64:
+		b.AppendFloat(v, 'f', -1, 64)
+	case []Attr: // 分组以嵌套对象的形式输出
+		b.AppendBytes('{')
+		for i, p := range v {
+			if i > 0 {
+				b.AppendBytes(',')
 			}
-			b.AppendBytes(val...)
+			b.AppendBytes('"').AppendString(p.K).AppendString(`":`)
+			appendJSONValue(b, p.V)
 		}
-
 		b.AppendBytes('}')
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			data = []byte(`"Err(` + err.Error() + `)"`)
+		}
+		b.AppendBytes(data...)
 	}
 }
 
+// 以 JSON 字符串的形式输出 s，包括两端的引号。
+//
+// [appendLogfmtQuoted] 采用的转义规则同样适用于 JSON。
+func appendJSONString(b *Buffer, s string) { appendLogfmtQuoted(b, []byte(s)) }
+
 // NewTermHandler 返回将 [Record] 写入终端的对象
 //
 // w 表示终端的接口，可以是 [os.Stderr] 或是 [os.Stdout]，
 // 如果是其它的实现者则会带控制字符一起输出；
 // foreColors 表示各类别信息的字符颜色，背景始终是默认色，未指定的颜色会从 [defaultTermColors] 获取；
 //
-// NOTE: 如果向 w 输出内容时出错，将会导致 panic。
+// 消息和属性中的控制字符默认会被转义，可以通过 [DisableSanitize] 禁用。
+//
+// NOTE: 如果向 w 输出内容时出错，且未通过 [WithErrorHandler] 指定错误处理函数，将会导致 panic。
 func NewTermHandler(w io.Writer, foreColors map[Level]colors.Color) Handler {
 	if w == nil {
 		panic("参数 w 不能为空")
@@ -324,7 +432,7 @@ func NewTermHandler(w io.Writer, foreColors map[Level]colors.Color) Handler {
 }
 
 func (h *termHandler) Handle(e *Record) {
-	if err := h.handle(e); err != nil {
+	if err := h.handle(e); err != nil && !e.HandleError(h, err) {
 		// 大概率是写入终端失败，直接 panic。
 		panic(fmt.Sprintf("NewTermHandler.Handle:%v\n", err))
 	}
@@ -346,6 +454,7 @@ func (h *termHandler) New(detail bool, lv Level, attrs []Attr) Handler {
 			attrs:  append(data, b.Bytes()...),
 			level:  []byte(l),
 			detail: detail,
+			raw:    h.raw,
 		},
 		foreColors: maps.Clone(h.foreColors),
 	}
@@ -8,10 +8,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/logs/v7/writers"
 )
 
 type (
@@ -57,7 +61,7 @@ func TestTextHandler(t *testing.T) {
 	l := New(NewTextHandler(buf), WithCreated(layout), WithLocation(true))
 	e := newRecord(a, l)
 	e.AppendCreated = func(b *Buffer) { b.AppendTime(now, l.createdFormat) }
-	e.with("m1", marshalObject("m1"))
+	e.With("m1", marshalObject("m1"))
 	e.Output(l.WARN())
 	a.Equal(buf.String(), "[WARN] "+now.Format(layout)+" path.go:20\tmsg k1=v1 k2=v2 m1=m1\n")
 
@@ -67,7 +71,7 @@ func TestTextHandler(t *testing.T) {
 	l = New(NewTextHandler(b1, b2, b3), WithCreated(layout), WithLocation(true))
 	e = newRecord(a, l)
 	e.AppendCreated = func(b *Buffer) { b.AppendTime(now, l.createdFormat) }
-	e.with("m1", marshalObject("m1"))
+	e.With("m1", marshalObject("m1"))
 	e.Output(l.WARN())
 	a.Equal(b1.String(), "[WARN] "+now.Format(layout)+" path.go:20\tmsg k1=v1 k2=v2 m1=m1\n")
 	a.Equal(b2.String(), "[WARN] "+now.Format(layout)+" path.go:20\tmsg k1=v1 k2=v2 m1=m1\n")
@@ -101,7 +105,7 @@ func TestJSONFormat(t *testing.T) {
 	l := New(NewJSONHandler(buf), WithCreated(layout), WithLocation(true))
 	e := newRecord(a, l)
 	e.AppendCreated = func(b *Buffer) { b.AppendTime(now, l.createdFormat) }
-	e.with("m1", marshalObject("m1"))
+	e.With("m1", marshalObject("m1"))
 	e.Output(l.WARN())
 	a.Equal(buf.String(), `{"level":"WARN","message":"msg","created":"`+now.Format(layout)+`","path":"path.go:20","attrs":[{"k1":"v1"},{"k2":"v2"},{"m1":"m1"}]}`)
 
@@ -110,7 +114,7 @@ func TestJSONFormat(t *testing.T) {
 	l = New(NewJSONHandler(b1, b2), WithCreated(layout), WithLocation(true))
 	e = newRecord(a, l)
 	e.AppendCreated = func(b *Buffer) { b.AppendTime(now, l.createdFormat) }
-	e.with("m1", marshalObject("m1"))
+	e.With("m1", marshalObject("m1"))
 	e.Output(l.WARN())
 	a.Equal(b1.String(), `{"level":"WARN","message":"msg","created":"`+now.Format(layout)+`","path":"path.go:20","attrs":[{"k1":"v1"},{"k2":"v2"},{"m1":"m1"}]}`).
 		Equal(b1.String(), b2.String())
@@ -121,7 +125,7 @@ func TestJSONFormat(t *testing.T) {
 	l = New(NewJSONHandler(buf), WithCreated(layout), WithLocation(true))
 	e = newRecord(a, l)
 	e.AppendCreated = func(b *Buffer) { b.AppendTime(now, l.createdFormat) }
-	e.with("m1", marshalObject("m1")).with("m2", marshalErrObject("m2"))
+	e.With("m1", marshalObject("m1")).With("m2", marshalErrObject("m2"))
 	e.Output(l.WARN())
 	a.Equal(buf.String(), `{"level":"WARN","message":"msg","created":"`+now.Format(layout)+`","path":"path.go:20","attrs":[{"k1":"v1"},{"k2":"v2"},{"m1":"m1"},{"m2":"Err(json: error calling MarshalJSON for type logs.marshalErrObject: marshal json error)"}]}`)
 
@@ -154,7 +158,7 @@ func TestTermHandler(t *testing.T) {
 	l := New(NewTermHandler(buf, nil), WithCreated(layout), WithLocation(true))
 	e := newRecord(a, l)
 	e.AppendCreated = func(b *Buffer) { b.AppendTime(now, l.createdFormat) }
-	e.with("m1", marshalObject("m1"))
+	e.With("m1", marshalObject("m1"))
 	e.Output(l.WARN())
 	a.Equal(buf.String(), "[\033[33;49mWARN\033[0m] "+now.Format(layout)+" path.go:20\tmsg k1=v1 k2=v2 m1=m1\n")
 
@@ -246,3 +250,53 @@ func TestMergeHandler(t *testing.T) {
 		True(json.Valid(jsonBuf.Bytes()), jsonBuf.String()).
 		Equal(textBuf.String(), "[WARN] warnf test a1=v1 a2=3\n")
 }
+
+func TestWithErrorHandler(t *testing.T) {
+	a := assert.New(t, false)
+
+	failed := writers.WriteFunc(func([]byte) (int, error) { return 0, errors.New("write error") })
+
+	var herr error
+	buf := new(bytes.Buffer)
+	l := New(MergeHandler(NewTextHandler(failed), NewJSONHandler(failed), NewTermHandler(failed, nil)),
+		WithErrorHandler(FallbackErrorHandler(NewTextHandler(buf), func(h Handler, e *Record, err error) {
+			a.NotNil(h).NotNil(e)
+			herr = err
+		})))
+	l.WARN().String("msg")
+	a.Equal(buf.String(), "[WARN] msg\n[WARN] msg\n[WARN] msg\n").
+		Equal(herr.Error(), "write error")
+
+	// fallback 本身也出错
+	buf.Reset()
+	cnt := 0
+	l = New(NewTextHandler(buf, failed), WithErrorHandler(FallbackErrorHandler(NewTermHandler(failed, nil), func(Handler, *Record, error) {
+		cnt++
+	})))
+	a.PanicString(func() { l.ERROR().String("msg") }, "write error")
+	a.Equal(cnt, 0)
+}
+
+func TestWithErrorHandler_frozen(t *testing.T) {
+	a := assert.New(t, false)
+
+	failed := writers.WriteFunc(func([]byte) (int, error) { return 0, errors.New("write error") })
+
+	var cnt atomic.Int32
+	l := New(NewTextHandler(failed), WithErrorHandler(func(_ Handler, e *Record, _ error) {
+		cnt.Add(1)
+		a.False(e.HandleError(nil, nil)) // 处于错误处理过程中
+	}))
+	l.INFO().String("msg")
+	a.Equal(cnt.Load(), 1)
+
+	f := l.NewRecord().DepthString(1, "msg").Freeze()
+
+	// 同一个快照在多个 goroutine 中输出，HandleError 不能修改快照。
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() { f.Output(l.ERROR()) })
+	}
+	wg.Wait()
+	a.Equal(cnt.Load(), 5).False(f.handlingErr)
+}
@@ -0,0 +1,294 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// 访问日志的格式
+const (
+	AccessStructured AccessFormat = iota // 以属性的形式输出各个字段
+	AccessCommon                         // Common Log Format
+	AccessCombined                       // Combined Log Format
+)
+
+// RequestIDHeader 默认的请求 ID 报头名称
+const RequestIDHeader = "X-Request-Id"
+
+const (
+	attrLogsContextKey contextKey = iota + 1
+	requestIDContextKey
+)
+
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+type (
+	// AccessFormat 访问日志的格式
+	AccessFormat int8
+
+	// MiddlewareOptions [NewMiddleware] 的配置项
+	MiddlewareOptions struct {
+		// Format 访问日志的格式
+		Format AccessFormat
+
+		// Level 访问日志的级别
+		//
+		// 默认值为 [LevelInfo]。
+		Level Level
+
+		// RequestIDHeader 请求 ID 的报头名称
+		//
+		// 如果请求中包含此报头，则采用该值作为请求 ID，否则生成新的 ID，
+		// 同时该值也会写入到响应的报头中。
+		// 为空表示采用 [RequestIDHeader]。
+		RequestIDHeader string
+
+		// TrustProxy 是否信任 X-Forwarded-For 和 X-Real-Ip 报头
+		//
+		// 如果为 true，会优先从这两个报头中获取客户端的 IP。
+		TrustProxy bool
+	}
+
+	middleware struct {
+		next    http.Handler
+		l       *Logs
+		format  AccessFormat
+		level   Level
+		idKey   string
+		trusted bool
+	}
+
+	responseWriter struct {
+		http.ResponseWriter
+		status int
+		size   int
+	}
+
+	// 处理请求时产生的 panic
+	//
+	// 调用堆栈属于详细信息，不会被转义，且不受 [WithDetail] 的影响。
+	panicError struct {
+		v     any
+		stack []byte
+	}
+)
+
+// NewMiddleware 返回记录访问日志的 [http.Handler] 中间件
+//
+// 每个请求都会通过 [Logs.New] 生成一个带有 method、path、remote_ip 和 request_id 属性的 [AttrLogs]，
+// 并保存在请求的 [context.Context] 中，可以通过 [AttrLogsFromContext] 获取。
+// 请求结束之后会输出一条包含状态码、响应大小和耗时的访问日志，并回收该 [AttrLogs]。
+//
+// 如果 next 发生 panic，会以 [LevelError] 输出 panic 信息及调用堆栈，并向客户端返回 500。
+//
+// o 可以为 nil，表示采用默认值。
+//
+// NOTE: 请求结束之后 [AttrLogs] 即被回收，不能在请求结束之后的 goroutine 中继续使用。
+func NewMiddleware(next http.Handler, l *Logs, o *MiddlewareOptions) http.Handler {
+	if o == nil {
+		o = &MiddlewareOptions{}
+	}
+
+	m := &middleware{
+		next:    next,
+		l:       l,
+		format:  o.Format,
+		level:   o.Level,
+		idKey:   o.RequestIDHeader,
+		trusted: o.TrustProxy,
+	}
+	if m.idKey == "" {
+		m.idKey = RequestIDHeader
+	}
+	return m
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	id := r.Header.Get(m.idKey)
+	if id == "" {
+		id = newRequestID()
+	}
+	w.Header().Set(m.idKey, id)
+
+	ip := m.remoteIP(r)
+	al := m.l.New(map[string]any{
+		"method":     r.Method,
+		"path":       r.URL.Path,
+		"remote_ip":  ip,
+		"request_id": id,
+	})
+	defer FreeAttrLogs(al)
+
+	ctx := context.WithValue(r.Context(), requestIDContextKey, id)
+	ctx = NewAttrLogsContext(ctx, al)
+	r = r.WithContext(ctx)
+	rw := &responseWriter{ResponseWriter: w}
+
+	defer func() {
+		if p := recover(); p != nil {
+			if p == http.ErrAbortHandler { // 由 net/http 处理
+				panic(p)
+			}
+
+			al.ERROR().WithContext(ctx).Error(&panicError{v: p, stack: debug.Stack()})
+			if rw.status == 0 {
+				rw.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+
+		m.access(al, rw, r, ip, start)
+	}()
+
+	m.next.ServeHTTP(rw, r)
+}
+
+func (m *middleware) access(al *AttrLogs, rw *responseWriter, r *http.Request, ip string, start time.Time) {
+	if !m.l.IsEnable(m.level) {
+		return
+	}
+
+	status := rw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if m.format == AccessStructured {
+		al.Logger(m.level).WithContext(r.Context()).
+			With("status", status).
+			With("bytes", rw.size).
+			With("latency", time.Since(start).String()).
+			String("access")
+		return
+	}
+
+	// CLF 是固定格式的内容，不需要附带 AttrLogs 中的属性。
+	user := "-"
+	if r.URL.User != nil && r.URL.User.Username() != "" {
+		user = r.URL.User.Username()
+	} else if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+
+	b := NewBuffer(false)
+	defer b.Free()
+	b.AppendString(ip).AppendString(" - ").AppendString(user).
+		AppendString(" [").AppendTime(start, clfTimeLayout).AppendString(`] "`).
+		AppendString(r.Method).AppendBytes(' ').AppendString(r.URL.RequestURI()).AppendBytes(' ').AppendString(r.Proto).
+		AppendString(`" `).AppendInt(int64(status), 10).AppendBytes(' ')
+	if rw.size > 0 {
+		b.AppendInt(int64(rw.size), 10)
+	} else {
+		b.AppendBytes('-')
+	}
+
+	if m.format == AccessCombined {
+		b.AppendString(` "`).AppendString(clfString(r.Referer())).
+			AppendString(`" "`).AppendString(clfString(r.UserAgent())).AppendBytes('"')
+	}
+
+	m.l.Logger(m.level).String(string(b.Bytes()))
+}
+
+func clfString(s string) string {
+	if s == "" {
+		return "-"
+	}
+	q := strconv.Quote(s)
+	return q[1 : len(q)-1]
+}
+
+func (m *middleware) remoteIP(r *http.Request) string {
+	if m.trusted {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			ip, _, _ := strings.Cut(xff, ",")
+			return strings.TrimSpace(ip)
+		}
+
+		if ip := r.Header.Get("X-Real-Ip"); ip != "" {
+			return ip
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func newRequestID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) Flush() { http.NewResponseController(w.ResponseWriter).Flush() }
+
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// NewAttrLogsContext 返回一个包含 l 的 [context.Context]
+//
+// 可以通过 [AttrLogsFromContext] 从返回值中获取 l。
+func NewAttrLogsContext(ctx context.Context, l *AttrLogs) context.Context {
+	return context.WithValue(ctx, attrLogsContextKey, l)
+}
+
+// AttrLogsFromContext 从 ctx 中获取由 [NewAttrLogsContext] 保存的 [AttrLogs]
+//
+// 如果不存在，则返回 nil。
+func AttrLogsFromContext(ctx context.Context) *AttrLogs {
+	if l, ok := ctx.Value(attrLogsContextKey).(*AttrLogs); ok {
+		return l
+	}
+	return nil
+}
+
+// RequestIDFromContext 从 ctx 中获取由 [NewMiddleware] 保存的请求 ID
+//
+// 如果不存在，则返回空字符串。
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+func (e *panicError) Error() string { return fmt.Sprintf("%v\n%s", e.v, e.stack) }
+
+func (e *panicError) FormatError(p xerrors.Printer) error {
+	p.Print(e.v)
+	p.Detail() // 之后输出的内容为调用堆栈
+	p.Printf("\n%s", e.stack)
+	return nil
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestNewMiddleware(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf))
+
+	h := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		al := AttrLogsFromContext(r.Context())
+		a.NotNil(al)
+		a.Equal(RequestIDFromContext(r.Context()), "id1")
+		al.INFO().String("in handler")
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("abc"))
+	}), l, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/path?k=v", nil)
+	r.Header.Set(RequestIDHeader, "id1")
+	h.ServeHTTP(w, r)
+	a.Equal(w.Code, http.StatusCreated).
+		Equal(w.Header().Get(RequestIDHeader), "id1").
+		Contains(buf.String(), "[INFO] in handler").
+		Contains(buf.String(), "method=GET").
+		Contains(buf.String(), "path=/path").
+		Contains(buf.String(), "remote_ip=192.0.2.1").
+		Contains(buf.String(), "request_id=id1").
+		Contains(buf.String(), "[INFO] access").
+		Contains(buf.String(), "status=201 bytes=3 latency=")
+
+	// 生成 request id
+	buf.Reset()
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/path", nil)
+	NewMiddleware(http.NotFoundHandler(), l, nil).ServeHTTP(w, r)
+	a.Length(w.Header().Get(RequestIDHeader), 32).
+		Contains(buf.String(), "request_id="+w.Header().Get(RequestIDHeader))
+}
+
+func TestNewMiddleware_CLF(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("abc")) })
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/path?k=v", nil)
+	r.SetBasicAuth("user", "pass")
+	NewMiddleware(next, l, &MiddlewareOptions{Format: AccessCommon, Level: LevelWarn}).ServeHTTP(w, r)
+	a.Contains(buf.String(), "[WARN] 192.0.2.1 - user [").
+		Contains(buf.String(), `] "GET /path?k=v HTTP/1.1" 200 3`+"\n").
+		NotContains(buf.String(), "request_id=")
+
+	buf.Reset()
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/path", nil)
+	r.Header.Set("User-Agent", "agent")
+	r.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+	NewMiddleware(next, l, &MiddlewareOptions{Format: AccessCombined, TrustProxy: true}).ServeHTTP(w, r)
+	a.Contains(buf.String(), "[INFO] 10.0.0.1 - - [").
+		Contains(buf.String(), `] "GET /path HTTP/1.1" 200 3 "-" "agent"`+"\n")
+}
+
+func TestNewMiddleware_panic(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf))
+
+	h := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("panic in handler")
+	}), l, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/path", nil)
+	h.ServeHTTP(w, r)
+	a.Equal(w.Code, http.StatusInternalServerError).
+		Contains(buf.String(), "[ERRO] panic in handler\n").
+		Contains(buf.String(), "http_test.go").
+		Contains(buf.String(), "status=500")
+
+	h = NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), l, nil)
+	a.PanicValue(func() { h.ServeHTTP(httptest.NewRecorder(), r) }, http.ErrAbortHandler)
+}
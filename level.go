@@ -30,6 +30,16 @@ var levelStrings = map[Level]string{
 	LevelFatal: "FATL",
 }
 
+// 各级别的严重程度，Level 本身的值并不是按严重程度排序的。
+var levelSeverities = map[Level]int{
+	LevelTrace: 0,
+	LevelDebug: 1,
+	LevelInfo:  2,
+	LevelWarn:  3,
+	LevelError: 4,
+	LevelFatal: 5,
+}
+
 func AllLevels() []Level {
 	return []Level{LevelInfo, LevelWarn, LevelTrace, LevelFatal, LevelError, LevelDebug}
 }
@@ -38,6 +48,9 @@ func IsValidLevel(l Level) bool { return l >= LevelInfo && l <= LevelFatal }
 
 func (l Level) String() string { return levelStrings[l] }
 
+// 严重程度，值越大越严重。
+func (l Level) severity() int { return levelSeverities[l] }
+
 func (l Level) MarshalText() ([]byte, error) { return []byte(l.String()), nil }
 
 func (l *Level) UnmarshalText(data []byte) error {
@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// LiveHandler 将日志实时推送给客户端的 [Handler]
+	//
+	// 同时也实现了 [http.Handler] 接口，客户端通过 [Server-Sent Events] 订阅日志。
+	// 可以通过 [MergeHandler] 与其它 [Handler] 一起使用：
+	//
+	//	live := logs.NewLiveHandler(100)
+	//	l := logs.New(logs.MergeHandler(logs.NewTextHandler(os.Stdout), live))
+	//	http.Handle("/logs", live)
+	//
+	// [Server-Sent Events]: https://html.spec.whatwg.org/multipage/server-sent-events.html
+	LiveHandler struct {
+		l     *live
+		lv    Level
+		attrs []Attr
+	}
+
+	live struct {
+		mux     sync.RWMutex
+		clients map[*liveClient]struct{}
+		count   atomic.Int32
+		buffer  int
+	}
+
+	liveClient struct {
+		ch      chan *Record
+		min     int        // 最低的严重程度
+		q       *RingQuery // 除级别之外的过滤条件，可以为空。
+		dropped atomic.Int64
+	}
+)
+
+// NewLiveHandler 声明 [LiveHandler]
+//
+// buffer 表示每个客户端可以缓存的日志数量，
+// 客户端处理速度过慢导致缓存已满时，新的日志将被丢弃，而不是阻塞日志的输出。
+// 被丢弃的数量会以 dropped 事件通知客户端。
+func NewLiveHandler(buffer int) *LiveHandler {
+	if buffer <= 0 {
+		panic("参数 buffer 必须大于 0")
+	}
+
+	return &LiveHandler{l: &live{clients: make(map[*liveClient]struct{}, 10), buffer: buffer}}
+}
+
+func (h *LiveHandler) Handle(e *Record) {
+	if h.l.count.Load() == 0 { // 没有订阅者
+		return
+	}
+
+	var r *Record
+	h.l.mux.RLock()
+	defer h.l.mux.RUnlock()
+	for c := range h.l.clients {
+		if h.lv.severity() < c.min {
+			continue
+		}
+
+		if r == nil {
+			r = freezeRecord(e, h.lv, h.attrs)
+		}
+		if c.q != nil && !c.q.match(r) {
+			continue
+		}
+
+		select {
+		case c.ch <- r:
+		default:
+			c.dropped.Add(1)
+		}
+	}
+}
+
+func (h *LiveHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	as := make([]Attr, 0, len(h.attrs)+len(attrs))
+	as = append(as, h.attrs...)
+	as = append(as, cloneAttrs(attrs)...)
+	return &LiveHandler{l: h.l, lv: lv, attrs: as}
+}
+
+// ServeHTTP 以 Server-Sent Events 的形式推送日志
+//
+// 每条日志对应一个 log 事件，被丢弃的日志数量对应 dropped 事件。
+// 支持以下查询参数：
+//   - level 最低的日志级别，比如 WARN 表示只推送 WARN、ERRO 和 FATL 的日志；
+//   - attr 以 key:value 的形式表示的属性，可以指定多个，只推送包含所有属性的日志；
+//   - msg 日志消息中需要包含的内容；
+//   - format 推送的格式，可以是 json 或是 text，默认为 json；
+func (h *LiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	c, err := h.newClient(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	text := r.URL.Query().Get("format") == "text"
+
+	// 在客户端收到报头之前完成订阅，保证客户端不会错过之后的日志。
+	h.l.subscribe(c)
+	defer h.l.unsubscribe(c)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case rr := <-c.ch:
+			buf.Reset()
+			if n := c.dropped.Swap(0); n > 0 {
+				buf.WriteString("event: dropped\ndata: ")
+				buf.WriteString(strconv.FormatInt(n, 10))
+				buf.WriteString("\n\n")
+			}
+
+			writeLiveEvent(buf, rr, text)
+
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *LiveHandler) newClient(r *http.Request) (*liveClient, error) {
+	q, err := parseRingQuery(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &liveClient{ch: make(chan *Record, h.l.buffer)}
+	if len(q.Levels) > 0 { // level 表示的是最低级别
+		c.min = q.Levels[0].severity()
+		q.Levels = nil
+	}
+	q.Start, q.End, q.Limit = time.Time{}, time.Time{}, 0 // 对实时日志没有意义
+	if q.Message != "" || len(q.Attrs) > 0 {
+		c.q = q
+	}
+	return c, nil
+}
+
+func writeLiveEvent(buf *bytes.Buffer, r *Record, text bool) {
+	buf.WriteString("event: log\n")
+
+	if !text {
+		data, err := json.Marshal(newRingRecord(r))
+		if err != nil {
+			data = []byte(strconv.Quote(err.Error()))
+		}
+		buf.WriteString("data: ")
+		buf.Write(data)
+		buf.WriteString("\n\n")
+		return
+	}
+
+	tb := new(bytes.Buffer)
+	th := &textHandler{w: tb, level: []byte("[" + r.Level().String() + "]")}
+	th.handle(r)                                // 写入 bytes.Buffer 不会出错
+	for line := range bytes.Lines(tb.Bytes()) { // 多行的内容需要拆分成多个 data
+		buf.WriteString("data: ")
+		buf.Write(bytes.TrimRight(line, "\r\n"))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+}
+
+func (l *live) subscribe(c *liveClient) {
+	l.mux.Lock()
+	l.clients[c] = struct{}{}
+	l.count.Add(1)
+	l.mux.Unlock()
+}
+
+func (l *live) unsubscribe(c *liveClient) {
+	l.mux.Lock()
+	delete(l.clients, c)
+	l.count.Add(-1)
+	l.mux.Unlock()
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+// 订阅 url 并返回读取事件的函数
+func subscribeLive(a *assert.Assertion, h *LiveHandler, url string) (next func() string, cancel context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	a.NotError(err)
+
+	// 服务端在输出报头之前已经完成订阅
+	resp, err := http.DefaultClient.Do(req)
+	a.NotError(err).Equal(resp.StatusCode, http.StatusOK).
+		Equal(resp.Header.Get("Content-Type"), "text/event-stream")
+
+	s := bufio.NewScanner(resp.Body)
+	return func() string {
+		lines := make([]string, 0, 2)
+		for s.Scan() {
+			if s.Text() == "" {
+				break
+			}
+			lines = append(lines, s.Text())
+		}
+		return strings.Join(lines, "\n")
+	}, cancel
+}
+
+func TestLiveHandler(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Panic(func() { NewLiveHandler(0) })
+
+	h := NewLiveHandler(10)
+	l := New(h, WithAttrs(map[string]any{"app": "test"}))
+	l.INFO().String("no subscriber") // 没有订阅者
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	next, cancel := subscribeLive(a, h, srv.URL+"?level=warn&attr=uid:5&format=text")
+	l.INFO().With("uid", 5).String("info")
+	l.WARN().With("uid", 6).String("warn6")
+	l.WARN().With("uid", 5).String("warn5\nline2")
+	l.ERROR().With("uid", 5).String("error")
+	a.Equal(next(), "event: log\ndata: [WARN] warn5\\nline2 app=test uid=5").
+		Equal(next(), "event: log\ndata: [ERRO] error app=test uid=5")
+	cancel()
+
+	next, cancel = subscribeLive(a, h, srv.URL)
+	defer cancel()
+	l.DEBUG().String("debug")
+	event := next()
+	a.True(strings.HasPrefix(event, `event: log`+"\n"+`data: {"level":"DBUG","time":"`), event).
+		True(strings.HasSuffix(event, `"message":"debug","attrs":{"app":"test"}}`), event)
+
+	// 客户端的缓存已满，未通过过滤的日志不占用缓存。
+	c := &liveClient{ch: make(chan *Record, 1), q: &RingQuery{Message: "x"}}
+	h.l.subscribe(c)
+	l.INFO().String("x1")
+	l.INFO().String("y")
+	l.INFO().String("x2")
+	l.INFO().String("x3")
+	a.Equal(c.dropped.Load(), 2).Equal((<-c.ch).Message(), "x1")
+	h.l.unsubscribe(c)
+
+	resp, err := http.Post(srv.URL, "text/plain", nil)
+	a.NotError(err).Equal(resp.StatusCode, http.StatusMethodNotAllowed)
+	a.NotError(resp.Body.Close())
+
+	resp, err = http.Get(srv.URL + "?level=xx")
+	a.NotError(err).Equal(resp.StatusCode, http.StatusBadRequest)
+	a.NotError(resp.Body.Close())
+}
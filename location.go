@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// 位置信息的格式
+const (
+	LocationFull     LocationFormat = iota // 完整的路径，比如 /home/user/logs/record.go:20
+	LocationShort                          // 文件名，比如 record.go:20
+	LocationRelative                       // 相对于模块或 GOPATH 的路径，比如 writers/rotate/rotate.go:20
+	LocationPackage                        // 文件所在的目录名加文件名，比如 logs/record.go:20
+	LocationFunc                           // 包名加函数名，比如 logs.(*Record).Output
+)
+
+// LocationFormat 位置信息的格式
+type LocationFormat int8
+
+// WithLocationFormat 指定位置信息的格式
+//
+// 默认为 [LocationFull]，仅在 [Logs.HasLocation] 为 true 时有效。
+// 除 [LocationFull] 之外的格式一般都不会包含构建环境中的路径，
+// 但是 [LocationRelative] 在无法确定文件所属的模块或 GOPATH 时，依然会输出完整的路径。
+//
+// 该设置仅影响 [Record.AppendLocation] 的输出，
+// [Handler] 依然可以通过 [Record.Caller] 获取完整的位置信息。
+func WithLocationFormat(f LocationFormat) Option { return func(l *Logs) { l.locationFormat = f } }
+
+// LocationFormat 位置信息的格式
+func (logs *Logs) LocationFormat() LocationFormat { return logs.locationFormat }
+
+func (f LocationFormat) append(b *Buffer, fr runtime.Frame) {
+	switch f {
+	case LocationShort:
+		b.AppendString(fileBase(fr.File))
+	case LocationRelative:
+		b.AppendString(relativeFile(fr))
+	case LocationPackage:
+		file := fr.File
+		if index := strings.LastIndexByte(file, '/'); index > 0 {
+			if dir := strings.LastIndexByte(file[:index], '/'); dir >= 0 {
+				file = file[dir+1:]
+			}
+		}
+		b.AppendString(file)
+	case LocationFunc:
+		b.AppendString(shortFuncName(fr.Function))
+		return
+	default:
+		b.AppendString(fr.File)
+	}
+
+	b.AppendBytes(':').AppendInt(int64(fr.Line), 10)
+}
+
+func fileBase(file string) string {
+	if index := strings.LastIndexByte(file, '/'); index >= 0 {
+		return file[index+1:]
+	}
+	return file
+}
+
+// 主模块的路径以及 main 包的导入路径
+var mainModule = sync.OnceValues(func() (mod, pkg string) {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path, strings.TrimSuffix(info.Path, ".test")
+	}
+	return "", ""
+})
+
+// 返回 fr.File 相对于模块或 GOPATH 的路径
+//
+// 依次尝试以下规则，都不符合时返回原始路径：
+//   - 非绝对路径，比如采用 -trimpath 编译的，原样返回；
+//   - 模块缓存中的文件，返回 pkg/mod 之后的部分，比如 github.com/issue9/errwrap@v0.3.3/buffer.go；
+//   - 主模块中的文件，返回相对于模块根目录的路径，比如 writers/rotate/rotate.go；
+//   - 所在目录以包的导入路径结尾的文件，比如 GOPATH 和 GOROOT 中的文件，返回导入路径加文件名，比如 net/http/server.go。
+func relativeFile(fr runtime.Frame) string {
+	file := fr.File
+	if !path.IsAbs(file) && !strings.Contains(file, ":/") { // 包含 :/ 的为 windows 下的绝对路径
+		return file
+	}
+
+	const modCache = "/pkg/mod/"
+	if index := strings.LastIndex(file, modCache); index >= 0 {
+		return file[index+len(modCache):]
+	}
+
+	pkg := funcPackage(fr.Function)
+	if pkg == "" {
+		return file
+	}
+	mod, mainPkg := mainModule()
+	if pkg == "main" {
+		pkg = mainPkg
+	}
+
+	dir := path.Dir(file)
+	if mod != "" && (pkg == mod || strings.HasPrefix(pkg, mod+"/")) {
+		if rel := pkg[len(mod):]; strings.HasSuffix(dir, rel) {
+			return file[len(dir)-len(rel)+1:]
+		}
+	}
+
+	if strings.HasSuffix(dir, "/"+pkg) {
+		return file[len(dir)-len(pkg):]
+	}
+
+	return file
+}
+
+// 去掉包路径之后的函数名
+//
+// 包名以导入路径的最后一个元素表示，主版本号会被忽略，
+// 比如 github.com/issue9/logs/v7.(*Record).Output 返回 logs.(*Record).Output，
+// gopkg.in/yaml%2ev3.Marshal 返回 yaml.Marshal。
+func shortFuncName(name string) string {
+	start := strings.LastIndexByte(name, '/') + 1
+	dot := strings.IndexByte(name[start:], '.')
+	if dot < 0 {
+		return name[start:]
+	}
+
+	pkg, rest := strings.ReplaceAll(name[start:start+dot], "%2e", "."), name[start+dot:]
+	if isMajorVersion(pkg) && start > 0 {
+		pkg = name[strings.LastIndexByte(name[:start-1], '/')+1 : start-1]
+	} else if index := strings.LastIndex(pkg, ".v"); index > 0 && isMajorVersion(pkg[index+1:]) {
+		pkg = pkg[:index]
+	}
+	return pkg + rest
+}
+
+// s 是否为 v2 等形式的主版本号
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// 从函数的完整名称中获取包的导入路径
+//
+// 比如 github.com/issue9/logs/v7.(*Record).Output 返回 github.com/issue9/logs/v7。
+// 函数名中包路径的最后一个元素中的 . 会被 runtime 转义为 %2e，返回值会将其还原，
+// 比如 gopkg.in/yaml%2ev3.Marshal 返回 gopkg.in/yaml.v3。
+func funcPackage(name string) string {
+	start := strings.LastIndexByte(name, '/') + 1
+	if index := strings.IndexByte(name[start:], '.'); index >= 0 {
+		return strings.ReplaceAll(name[:start+index], "%2e", ".")
+	}
+	return ""
+}
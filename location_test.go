@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestLocationFormat(t *testing.T) {
+	a := assert.New(t, false)
+
+	f := runtime.Frame{File: "/home/user/logs/record.go", Line: 20, Function: "github.com/issue9/logs/v7.(*Record).Output"}
+	data := map[LocationFormat]string{
+		LocationFull:     "/home/user/logs/record.go:20",
+		LocationShort:    "record.go:20",
+		LocationRelative: "record.go:20",
+		LocationPackage:  "logs/record.go:20",
+		LocationFunc:     "logs.(*Record).Output",
+	}
+	for format, want := range data {
+		b := NewBuffer(false)
+		format.append(b, f)
+		a.Equal(string(b.Bytes()), want, format)
+		b.Free()
+	}
+
+	// 非标准的内容
+	f = runtime.Frame{File: "record.go", Line: 20, Function: "main.main"}
+	data = map[LocationFormat]string{
+		LocationShort:    "record.go:20",
+		LocationRelative: "record.go:20",
+		LocationPackage:  "record.go:20",
+		LocationFunc:     "main.main",
+	}
+	for format, want := range data {
+		b := NewBuffer(false)
+		format.append(b, f)
+		a.Equal(string(b.Bytes()), want, format)
+		b.Free()
+	}
+}
+
+func TestWithLocationFormat(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	l := New(NewTextHandler(buf), WithLocation(true), WithLocationFormat(LocationRelative))
+	a.Equal(l.LocationFormat(), LocationRelative)
+	var pc uintptr
+	var caller runtime.Frame
+	l.INFO().Print("msg")
+	l = New(MergeHandler(NewTextHandler(buf), handleFunc(func(r *Record) { pc, caller = r.PC(), r.Caller() })),
+		WithLocation(true), WithLocationFormat(LocationFunc))
+	l.INFO().Print("msg")
+	a.Equal(buf.String(), "[INFO] location_test.go:58\tmsg\n"+
+		"[INFO] logs.TestWithLocationFormat\tmsg\n")
+
+	// 依然可以获取完整的位置信息
+	fr, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	a.True(strings.HasSuffix(caller.File, "/location_test.go")).
+		Equal(caller.Line, 61).
+		Equal(caller.Function, "github.com/issue9/logs/v7.TestWithLocationFormat").
+		Equal(fr.Line, caller.Line)
+}
+
+func TestRelativeFile(t *testing.T) {
+	a := assert.New(t, false)
+
+	data := []struct {
+		file, function, want string
+	}{
+		{file: "/home/user/logs/writers/rotate/rotate.go", function: "github.com/issue9/logs/v7/writers/rotate.New", want: "writers/rotate/rotate.go"},
+		{file: "/home/user/go/pkg/mod/gopkg.in/yaml.v3@v3.0.1/yaml.go", function: "gopkg.in/yaml%2ev3.Marshal", want: "gopkg.in/yaml.v3@v3.0.1/yaml.go"},
+		{file: "/home/user/go/src/example.com/app/app.go", function: "example.com/app.Run", want: "example.com/app/app.go"},
+		{file: "/usr/local/go/src/net/http/server.go", function: "net/http.(*conn).serve", want: "net/http/server.go"},
+		{file: "C:/go/src/net/http/server.go", function: "net/http.(*conn).serve", want: "net/http/server.go"},
+		{file: "github.com/issue9/logs/v7/record.go", function: "github.com/issue9/logs/v7.New", want: "github.com/issue9/logs/v7/record.go"}, // -trimpath
+		{file: "/tmp/app/main.go", function: "", want: "/tmp/app/main.go"},
+	}
+	for _, item := range data {
+		a.Equal(relativeFile(runtime.Frame{File: item.file, Function: item.function}), item.want, item.file)
+	}
+}
+
+func TestShortFuncName(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(shortFuncName("github.com/issue9/logs/v7.(*Record).Output"), "logs.(*Record).Output").
+		Equal(shortFuncName("github.com/issue9/logs/v7/writers/rotate.New"), "rotate.New").
+		Equal(shortFuncName("gopkg.in/yaml%2ev3.Marshal"), "yaml.Marshal").
+		Equal(shortFuncName("net/http.(*conn).serve"), "http.(*conn).serve").
+		Equal(shortFuncName("main.main.func1"), "main.main.func1").
+		Equal(shortFuncName("v2.F"), "v2.F")
+}
@@ -0,0 +1,258 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/issue9/logs/v7/writers"
+)
+
+const hexDigits = "0123456789abcdef"
+
+type logfmtHandler struct {
+	w   io.Writer
+	mux sync.Mutex
+
+	attrs  []byte // 预编译的属性值
+	level  []byte // 预处理的 level 内容
+	detail bool
+}
+
+// NewLogfmtHandler 返回将 [Record] 以 [logfmt] 的形式写入 w 的对象
+//
+// 输出格式为：
+//
+//	level=WARN time=15:04:05.000 caller=path.go:20 msg="message" k1=v1 k2="v 2"
+//
+// 其中 time 和 caller 只在 [Record] 中存在相应的数据时才会输出。
+// 值中包含空格、=、引号或是控制字符时会以双引号包含并转义，消息中的换行符也会被转义，
+// 以保证每条日志只占一行。
+// 键名中的空格、=、引号和控制字符会被替换为下划线。
+//
+// 各类型的值的编码方式如下：
+//   - [time.Time] 采用 [time.RFC3339Nano] 格式；
+//   - [time.Duration] 采用 [time.Duration.String]；
+//   - []byte 作为字符串输出；
+//   - error 采用 Error() 的返回值；
+//   - [fmt.Stringer] 采用 String() 的返回值；
+//   - []Attr 以 group.key=value 的形式展开；
+//
+// NOTE: 如果向 w 输出内容时出错，且未通过 [WithErrorHandler] 指定错误处理函数，
+// 会将错误信息输出到终端作为最后的处理方式。
+//
+// [logfmt]: https://brandur.org/logfmt
+func NewLogfmtHandler(w ...io.Writer) Handler {
+	return &logfmtHandler{w: writers.New(w...)}
+}
+
+func (h *logfmtHandler) Handle(e *Record) {
+	if err := h.handle(e); err != nil && !e.HandleError(h, err) {
+		fmt.Fprintf(os.Stderr, "NewLogfmtHandler.Handle:%v\n", err)
+	}
+}
+
+func (h *logfmtHandler) handle(e *Record) error {
+	b := NewBuffer(h.detail)
+	defer b.Free()
+
+	b.AppendBytes(h.level...)
+
+	if e.AppendCreated != nil {
+		b.AppendString(" time=")
+		start := b.Len()
+		b.AppendFunc(e.AppendCreated)
+		quoteLogfmt(b, start)
+	}
+
+	if e.AppendLocation != nil {
+		b.AppendString(" caller=")
+		start := b.Len()
+		b.AppendFunc(e.AppendLocation)
+		quoteLogfmt(b, start)
+	}
+
+	for _, p := range e.Attrs { // 与 textHandler 保持一致，链路追踪的相关属性放在消息之前。
+		if isTraceKey(p.K) {
+			h.buildAttr(b, "", p)
+		}
+	}
+
+	b.AppendString(" msg=")
+	start := b.Len()
+	b.AppendFunc(e.AppendMessage)
+	quoteLogfmt(b, start)
+
+	b.AppendBytes(h.attrs...)
+
+	for _, p := range e.Attrs {
+		if !isTraceKey(p.K) {
+			h.buildAttr(b, "", p)
+		}
+	}
+
+	b.AppendBytes('\n')
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+func (h *logfmtHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	b := NewBuffer(false)
+	defer b.Free()
+	for _, p := range attrs {
+		h.buildAttr(b, "", p)
+	}
+
+	data := make([]byte, 0, b.Len()+len(h.attrs))
+	data = append(data, h.attrs...)
+
+	return &logfmtHandler{
+		w: h.w,
+
+		attrs:  append(data, b.Bytes()...),
+		level:  []byte("level=" + lv.String()),
+		detail: detail,
+	}
+}
+
+func (h *logfmtHandler) buildAttr(b *Buffer, prefix string, p Attr) {
+	if g, ok := p.V.([]Attr); ok {
+		for _, pp := range g {
+			h.buildAttr(b, prefix+p.K+".", pp)
+		}
+		return
+	}
+
+	b.AppendBytes(' ')
+	appendLogfmtKey(b, prefix)
+	appendLogfmtKey(b, p.K)
+	b.AppendBytes('=')
+
+	start := b.Len()
+	appendLogfmtValue(b, p.V)
+	quoteLogfmt(b, start)
+}
+
+func appendLogfmtKey(b *Buffer, k string) {
+	for i := 0; i < len(k); i++ {
+		if c := k[i]; c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			b.AppendBytes('_')
+		} else {
+			b.AppendBytes(c)
+		}
+	}
+}
+
+func appendLogfmtValue(b *Buffer, val any) {
+	switch v := val.(type) {
+	case nil:
+	case string:
+		b.AppendString(v)
+	case []byte:
+		b.AppendBytes(v...)
+	case bool:
+		if v {
+			b.AppendString("true")
+		} else {
+			b.AppendString("false")
+		}
+	case time.Time:
+		b.AppendTime(v, time.RFC3339Nano)
+	case time.Duration:
+		b.AppendString(v.String())
+	case error:
+		b.AppendString(v.Error())
+	case fmt.Stringer:
+		b.AppendString(v.String())
+	default:
+		appendTextValue(b, v)
+	}
+}
+
+// 如果 b 中从 start 开始的内容需要引号，则将其转换为带引号的形式。
+func quoteLogfmt(b *Buffer, start int) {
+	if !needLogfmtQuote(b.data[start:]) {
+		return
+	}
+
+	tmp := NewBuffer(false)
+	defer tmp.Free()
+	tmp.AppendBytes(b.data[start:]...)
+
+	b.data = b.data[:start]
+	appendLogfmtQuoted(b, tmp.data)
+}
+
+func needLogfmtQuote(data []byte) bool {
+	if len(data) == 0 {
+		return true
+	}
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c < utf8.RuneSelf {
+			if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+				return true
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func appendLogfmtQuoted(b *Buffer, data []byte) {
+	b.AppendBytes('"')
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(data[i:])
+			switch {
+			case unicode.IsPrint(r) && !(r == utf8.RuneError && size == 1):
+				b.AppendBytes(data[i : i+size]...)
+			case r <= 0xffff && r != utf8.RuneError:
+				b.AppendString(`\u`).AppendBytes(hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+			default: // 无效的 UTF-8 编码
+				b.AppendString(`\ufffd`)
+			}
+			i += size
+			continue
+		}
+
+		switch c {
+		case '"', '\\':
+			b.AppendBytes('\\', c)
+		case '\n':
+			b.AppendString(`\n`)
+		case '\r':
+			b.AppendString(`\r`)
+		case '\t':
+			b.AppendString(`\t`)
+		default:
+			if c < ' ' || c == 0x7f {
+				b.AppendString(`\u00`).AppendBytes(hexDigits[c>>4], hexDigits[c&0xf])
+			} else {
+				b.AppendBytes(c)
+			}
+		}
+		i++
+	}
+	b.AppendBytes('"')
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+type stringerObject struct{}
+
+func (stringerObject) String() string { return "stringer" }
+
+func TestLogfmtHandler(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	h := NewLogfmtHandler(buf)
+	l := New(h, WithLocation(true))
+	e := newRecord(a, l)
+	e.AppendMessage = func(b *Buffer) { b.AppendString("line1\nline2") }
+	e.AppendCreated = func(b *Buffer) { b.AppendString("2026-01-02 15:04:05") }
+	e.Attrs = append(e.Attrs,
+		Attr{K: "space", V: "a b"},
+		Attr{K: "eq", V: "a=b"},
+		Attr{K: "quote", V: `say "hi"`},
+		Attr{K: "empty", V: ""},
+		Attr{K: "bytes", V: []byte("abc")},
+		Attr{K: "dur", V: time.Second},
+		Attr{K: "time", V: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Attr{K: "err", V: errors.New("not found")},
+		Attr{K: "stringer", V: stringerObject{}},
+		Attr{K: "bool", V: true},
+		Attr{K: "nil", V: nil},
+		Attr{K: "ctrl", V: "a\x1b[31mb\tc"},
+		Attr{K: "bad key", V: 1},
+		Attr{K: "g", V: []Attr{{K: "k", V: 1.5}}},
+		Attr{K: TraceIDKey, V: "123"},
+	)
+	h = h.New(false, LevelWarn, []Attr{{K: "a1", V: "中文"}})
+	h.Handle(e)
+	a.Equal(buf.String(), `level=WARN time="2026-01-02 15:04:05" caller=path.go:20 trace_id=123 msg="line1\nline2" a1=中文 k1=v1 k2=v2`+
+		` space="a b" eq="a=b" quote="say \"hi\"" empty="" bytes=abc dur=1s time=2026-01-02T03:04:05Z err="not found"`+
+		` stringer=stringer bool=true nil="" ctrl="a\u001b[31mb\tc" bad_key=1 g.k=1.5`+"\n")
+
+	// Handler.New().New()
+	buf.Reset()
+	e = newRecord(a, l)
+	e.AppendLocation = nil
+	h.New(false, LevelInfo, []Attr{{K: "a2", V: "\xff"}}).Handle(e)
+	a.Equal(buf.String(), `level=INFO msg=msg a1=中文 a2="\ufffd" k1=v1 k2=v2`+"\n")
+
+	// 通过 Logs 输出
+	buf.Reset()
+	l = New(NewLogfmtHandler(buf))
+	l.ERROR().With("uid", 5).Error(errors.New("a\r\nb"))
+	a.Equal(buf.String(), `level=ERRO msg="a\r\nb" uid=5`+"\n")
+}
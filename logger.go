@@ -18,15 +18,16 @@ var loggerPool = &sync.Pool{New: func() any { return &Logger{} }}
 
 // Logger 日志对象
 type Logger struct {
-	lv   Level
-	logs *Logs
-	h    Handler
+	lv    Level
+	logs  *Logs
+	h     Handler
+	force bool // 忽略 Logs.IsEnable 的设置，始终启用。
 }
 
 // IsEnable 当前日志是否会真实输出内容
 //
-// 此返回值与 [Logs.IsEnable] 返回的值是相同的。
-func (l *Logger) IsEnable() bool { return l.logs.IsEnable(l.Level()) }
+// 除了由 [Logs.NewTail] 生成的对象之外，此返回值与 [Logs.IsEnable] 返回的值是相同的。
+func (l *Logger) IsEnable() bool { return l.force || l.logs.IsEnable(l.Level()) }
 
 // Level 当前日志的类别
 func (l *Logger) Level() Level { return l.lv }
@@ -46,40 +47,50 @@ func (l *Logger) With(name string, val any) Recorder {
 
 	r := withRecordPool.Get().(*withRecorder)
 	r.l = l
-	r.r = l.logs.NewRecord().with(name, val)
+	r.r = l.newRecord().With(name, val)
 	return r
 }
 
 func (l *Logger) Error(err error) {
 	if l.IsEnable() {
-		l.logs.NewRecord().DepthError(3, err).Output(l)
+		l.newRecord().DepthError(3, err).Output(l)
 	}
 }
 
 func (l *Logger) String(s string) {
 	if l.IsEnable() {
-		l.logs.NewRecord().DepthString(3, s).Output(l)
+		l.newRecord().DepthString(3, s).Output(l)
 	}
 }
 
 func (l *Logger) LocaleString(s localeutil.Stringer) {
 	if l.IsEnable() {
-		l.logs.NewRecord().DepthLocaleString(3, s).Output(l)
+		l.newRecord().DepthLocaleString(3, s).Output(l)
 	}
 }
 
 func (l *Logger) Print(v ...any) {
 	if l.IsEnable() {
-		l.logs.NewRecord().DepthPrint(3, v...).Output(l)
+		l.newRecord().DepthPrint(3, v...).Output(l)
 	}
 }
 
 func (l *Logger) Printf(format string, v ...any) {
 	if l.IsEnable() {
-		l.logs.NewRecord().DepthPrintf(3, format, v...).Output(l)
+		l.newRecord().DepthPrintf(3, format, v...).Output(l)
 	}
 }
 
+// 创建级别为 l.lv 的 [Record]
+//
+// 在输出内容之前就确定级别，才能决定是否需要记录调用堆栈。
+func (l *Logger) newRecord() *Record {
+	e := l.logs.NewRecord()
+	e.lv = l.lv
+	e.leveled = true
+	return e
+}
+
 // New 根据当前对象派生新的 [Logger]
 //
 // 新对象会继承当前对象的 [Logger.attrs] 同时还拥有参数 attrs。
@@ -87,6 +98,7 @@ func (l *Logger) New(attrs map[string]any) *Logger {
 	ll := loggerPool.Get().(*Logger)
 	ll.lv = l.lv
 	ll.logs = l.logs
+	ll.force = l.force
 	ll.h = l.Handler().New(l.logs.detail, l.Level(), map2Slice(l.logs.printer, attrs))
 	return ll
 }
@@ -106,7 +118,7 @@ func (l *Logger) LogLogger() *log.Logger {
 // 仅供 [Logger.LogLogger] 使用，因为 depth 值的关系，只有固定的调用层级关系才能正常显示行号。
 func (l *Logger) asWriter() io.Writer {
 	return writers.WriteFunc(func(data []byte) (int, error) {
-		l.logs.NewRecord().DepthString(6, string(data)).Output(l)
+		l.newRecord().DepthString(6, string(data)).Output(l)
 		return len(data), nil
 	})
 }
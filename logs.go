@@ -6,9 +6,12 @@
 package logs
 
 import (
+	"log/slog"
 	"maps"
+	"runtime"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/issue9/localeutil"
 )
@@ -20,12 +23,22 @@ var attrLogsPool = &sync.Pool{
 type Logs struct {
 	loggers map[Level]*Logger
 
-	levels        []Level
-	attrs         map[string]any
-	location      bool
-	detail        bool
-	createdFormat string
-	printer       *localeutil.Printer
+	levels         []Level
+	attrs          map[string]any
+	location       bool
+	detail         bool
+	createdFormat  string
+	printer        *localeutil.Printer
+	errHandler     func(Handler, *Record, error)
+	extractors     []ContextExtractor
+	replaceAttr    func([]string, slog.Attr) slog.Attr
+	clock          func() time.Time
+	caller         func(runtime.Frame) runtime.Frame
+	locationFormat LocationFormat
+	stack          bool
+	stackLevel     Level
+	stackSkip      []string
+	errorTree      bool
 }
 
 // AttrLogs 带有固定属性的日志
@@ -33,6 +46,7 @@ type AttrLogs struct {
 	attrs   map[string]any
 	loggers map[Level]*Logger
 	logs    *Logs
+	tail    *tailBuffer
 }
 
 // Marshaler 定义了序列化日志属性的方法
@@ -43,6 +57,25 @@ type Marshaler interface {
 	MarshalLog() string
 }
 
+// Loggers 按级别返回 [Logger] 的对象
+//
+// [Logs] 和 [AttrLogs] 都实现了该接口，
+// 由 [AttrLogs] 返回的各级别 [Logger] 都带有相同的属性。
+type Loggers interface {
+	Logger(Level) *Logger
+}
+
+// 从 l 中获取 lv 指定的各级别 [Logger]
+//
+// [AttrLogs.Logger] 不能在多个 goroutine 中同时调用，所以需要提前获取。
+func resolveLoggers(l Loggers, lv ...Level) map[Level]*Logger {
+	loggers := make(map[Level]*Logger, len(lv))
+	for _, v := range lv {
+		loggers[v] = l.Logger(v)
+	}
+	return loggers
+}
+
 func map2Slice(p *localeutil.Printer, attrs map[string]any) []Attr {
 	pairs := make([]Attr, 0, len(attrs))
 
@@ -127,11 +160,14 @@ func (logs *Logs) New(attrs map[string]any) *AttrLogs {
 	l.attrs = attrs
 	clear(l.loggers)
 	l.logs = logs
+	l.tail = nil
 	return l
 }
 
 // IsEnable 指定级别日志是否会真实被启用
-func (logs *AttrLogs) IsEnable(l Level) bool { return logs.logs.IsEnable(l) }
+func (logs *AttrLogs) IsEnable(l Level) bool {
+	return (logs.tail != nil && logs.tail.isBuffered(l)) || logs.logs.IsEnable(l)
+}
 
 func (logs *AttrLogs) INFO() *Logger { return logs.Logger(LevelInfo) }
 
@@ -148,7 +184,11 @@ func (logs *AttrLogs) FATAL() *Logger { return logs.Logger(LevelFatal) }
 // Logger 返回指定级别的日志对象
 func (logs *AttrLogs) Logger(lv Level) *Logger {
 	if _, found := logs.loggers[lv]; !found {
-		logs.loggers[lv] = logs.logs.Logger(lv).New(logs.attrs)
+		l := logs.logs.Logger(lv).New(logs.attrs)
+		if logs.tail != nil {
+			logs.tail.wrap(l)
+		}
+		logs.loggers[lv] = l
 	}
 	return logs.loggers[lv]
 }
@@ -171,8 +211,15 @@ func (logs *AttrLogs) NewRecord() *Record { return logs.logs.NewRecord() }
 // 如果需要频繁地生成 [AttrLogs] 且其生命周期都有固定的销毁时间点，
 // 可以用此方法达到复用 [AttrLogs] 以达到些许性能提升。
 //
+// 如果 logs 是由 [Logs.NewTail] 生成的，尚未输出的缓存日志会被丢弃。
+//
 // NOTE: 此操作会让 logs 不再可用。
 func FreeAttrLogs(logs *AttrLogs) {
+	if logs.tail != nil {
+		logs.tail.discard()
+		logs.tail = nil
+	}
+
 	for _, l := range logs.loggers {
 		if l != nil {
 			l.free()
@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package logstest 为测试提供的日志工具
+//
+// [Handler] 以结构化的形式记录所有的日志，可以直接对日志进行断言：
+//
+//	h := logstest.NewHandler(t)
+//	l := logs.New(h)
+//	l.ERROR().With("uid", 5).String("not found")
+//	h.HasRecord(logs.LevelError, "not found", logs.Attr{K: "uid", V: 5})
+//
+// 由 [NewTBHandler] 返回的对象则将日志输出到 [testing.TB.Log]，
+// 这样日志会与出错的测试显示在一起。
+package logstest
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/issue9/logs/v7"
+)
+
+type (
+	// Handler 记录所有日志的 [logs.Handler]
+	//
+	// 可以在多个 goroutine 中同时使用，包括调用了 [testing.T.Parallel] 的测试。
+	Handler struct {
+		r     *recorder
+		lv    logs.Level
+		attrs []logs.Attr
+	}
+
+	// Record 由 [Handler] 记录的日志
+	//
+	// 内容在记录时即已生成，之后不会再发生变化。
+	Record struct {
+		Level   logs.Level
+		Time    time.Time
+		Message string
+		Err     error
+		Caller  runtime.Frame
+
+		// Attrs 日志的属性
+		//
+		// 包含了由 [logs.Handler.New] 附加的属性。
+		Attrs []logs.Attr
+	}
+
+	recorder struct {
+		tb      testing.TB
+		mux     sync.Mutex
+		records []*Record
+	}
+
+	tbWriter struct {
+		tb testing.TB
+	}
+)
+
+// NewHandler 声明 [Handler]
+//
+// tb 用于报告 [Handler.HasRecord] 等断言方法的错误。
+func NewHandler(tb testing.TB) *Handler {
+	return &Handler{r: &recorder{tb: tb}}
+}
+
+func (h *Handler) Handle(e *logs.Record) {
+	e = e.Freeze()
+
+	attrs := make([]logs.Attr, 0, len(h.attrs)+len(e.Attrs))
+	attrs = append(attrs, h.attrs...)
+	attrs = append(attrs, e.Attrs...)
+
+	r := &Record{
+		Level:   h.lv,
+		Time:    e.Time(),
+		Message: e.Message(),
+		Err:     e.Err(),
+		Caller:  e.Caller(),
+		Attrs:   attrs,
+	}
+
+	h.r.mux.Lock()
+	h.r.records = append(h.r.records, r)
+	h.r.mux.Unlock()
+}
+
+func (h *Handler) New(_ bool, lv logs.Level, attrs []logs.Attr) logs.Handler {
+	as := make([]logs.Attr, 0, len(h.attrs)+len(attrs))
+	as = append(as, h.attrs...)
+	as = append(as, attrs...)
+	return &Handler{r: h.r, lv: lv, attrs: as}
+}
+
+// Records 按输出顺序返回所有的日志
+//
+// 返回的对象由所有调用者共享，不应该修改其内容。
+func (h *Handler) Records() []*Record {
+	h.r.mux.Lock()
+	defer h.r.mux.Unlock()
+	return slices.Clone(h.r.records)
+}
+
+// Count 符合条件的日志数量
+//
+// msg 表示日志消息中需要包含的内容，为空表示不作限制；
+// attrs 表示日志需要包含的属性，值以 [reflect.DeepEqual] 进行比较。
+func (h *Handler) Count(lv logs.Level, msg string, attrs ...logs.Attr) int {
+	var n int
+	for _, r := range h.Records() {
+		if r.match(lv, msg, attrs) {
+			n++
+		}
+	}
+	return n
+}
+
+// HasRecord 是否存在符合条件的日志
+//
+// 参数与 [Handler.Count] 相同。
+// 不存在时会通过 [testing.TB.Errorf] 报告错误，并列出所有已经记录的日志。
+func (h *Handler) HasRecord(lv logs.Level, msg string, attrs ...logs.Attr) bool {
+	h.r.tb.Helper()
+
+	rs := h.Records()
+	if slices.ContainsFunc(rs, func(r *Record) bool { return r.match(lv, msg, attrs) }) {
+		return true
+	}
+
+	var sb strings.Builder
+	for _, r := range rs {
+		sb.WriteString("\n\t")
+		sb.WriteString(r.String())
+	}
+	h.r.tb.Errorf("不存在级别为 %s、消息包含 %q 且属性为 %v 的日志，已记录的日志：%s", lv, msg, attrs, sb.String())
+	return false
+}
+
+// Reset 清除所有已经记录的日志
+func (h *Handler) Reset() {
+	h.r.mux.Lock()
+	h.r.records = nil
+	h.r.mux.Unlock()
+}
+
+func (r *Record) match(lv logs.Level, msg string, attrs []logs.Attr) bool {
+	if r.Level != lv || !strings.Contains(r.Message, msg) {
+		return false
+	}
+
+	for _, a := range attrs {
+		if !slices.ContainsFunc(r.Attrs, func(ra logs.Attr) bool { return ra.K == a.K && reflect.DeepEqual(ra.V, a.V) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// String 以 [LEVEL] message k=v 的形式返回日志内容
+func (r *Record) String() string {
+	var sb strings.Builder
+	sb.WriteString("[" + r.Level.String() + "] " + r.Message)
+	for _, a := range r.Attrs {
+		fmt.Fprintf(&sb, " %s=%v", a.K, a.V)
+	}
+	return sb.String()
+}
+
+// NewTBHandler 返回将日志输出到 [testing.TB.Log] 的 [logs.Handler]
+//
+// 输出格式与 [logs.NewTextHandler] 相同。
+//
+// NOTE: 在测试结束之后继续输出日志会导致 panic，这是由 [testing] 包决定的。
+func NewTBHandler(tb testing.TB) logs.Handler {
+	return logs.NewTextHandler(&tbWriter{tb: tb})
+}
+
+func (w *tbWriter) Write(p []byte) (int, error) {
+	w.tb.Helper()
+	w.tb.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logstest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/logs/v7"
+)
+
+var _ logs.Handler = &Handler{}
+
+// 记录错误信息的 testing.TB
+type fakeTB struct {
+	testing.TB
+	mux  sync.Mutex
+	errs []string
+	logs []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, v ...any) {
+	tb.mux.Lock()
+	defer tb.mux.Unlock()
+	tb.errs = append(tb.errs, fmt.Sprintf(format, v...))
+}
+
+func (tb *fakeTB) Log(v ...any) {
+	tb.mux.Lock()
+	defer tb.mux.Unlock()
+	tb.logs = append(tb.logs, fmt.Sprint(v...))
+}
+
+func TestHandler(t *testing.T) {
+	a := assert.New(t, false)
+	tb := &fakeTB{TB: t}
+
+	h := NewHandler(tb)
+	l := logs.New(h, logs.WithAttrs(map[string]any{"app": "test"}), logs.WithLocation(true))
+	err := errors.New("not found")
+	l.ERROR().With("uid", 5).Error(err)
+	l.INFO().String("info")
+
+	rs := h.Records()
+	a.Length(rs, 2).
+		Equal(rs[0].Level, logs.LevelError).
+		Equal(rs[0].Message, "not found").
+		Equal(rs[0].Err, err).
+		Equal(rs[0].Attrs, []logs.Attr{{K: "app", V: "test"}, {K: "uid", V: 5}}).
+		True(strings.HasSuffix(rs[0].Caller.File, "logstest_test.go")).
+		False(rs[0].Time.IsZero()).
+		Equal(rs[1].String(), "[INFO] info app=test")
+
+	a.True(h.HasRecord(logs.LevelError, "not", logs.Attr{K: "uid", V: 5})).
+		True(h.HasRecord(logs.LevelInfo, "")).
+		Equal(h.Count(logs.LevelError, ""), 1).
+		Equal(h.Count(logs.LevelError, "", logs.Attr{K: "uid", V: "5"}), 0).
+		Empty(tb.errs)
+
+	a.False(h.HasRecord(logs.LevelWarn, "not")).
+		Length(tb.errs, 1).
+		Contains(tb.errs[0], "[ERRO] not found app=test uid=5")
+
+	h.Reset()
+	a.Empty(h.Records()).Equal(h.Count(logs.LevelInfo, ""), 0)
+}
+
+func TestHandler_parallel(t *testing.T) {
+	h := NewHandler(t)
+	l := logs.New(h)
+
+	for i := range 10 {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			for range 100 {
+				l.INFO().With("i", i).String("msg")
+			}
+		})
+	}
+
+	t.Cleanup(func() {
+		a := assert.New(t, false)
+		a.Equal(h.Count(logs.LevelInfo, "msg"), 1000).
+			Equal(h.Count(logs.LevelInfo, "msg", logs.Attr{K: "i", V: 5}), 100)
+	})
+}
+
+func TestNewTBHandler(t *testing.T) {
+	a := assert.New(t, false)
+	tb := &fakeTB{TB: t}
+
+	l := logs.New(NewTBHandler(tb))
+	l.WARN().With("k", "v").String("warn")
+	l.INFO().String("info")
+	a.Equal(tb.logs, []string{"[WARN] warn k=v", "[INFO] info"})
+}
@@ -6,7 +6,10 @@ package logs
 
 import (
 	"fmt"
+	"log"
 	"log/slog"
+	"runtime"
+	"time"
 
 	"github.com/issue9/localeutil"
 )
@@ -25,7 +28,21 @@ const (
 type Option func(*Logs)
 
 // WithStd 是否接管标准库中 log 和 log/slog 中的全局输出函数
-func WithStd() Option { return func(l *Logs) { slog.SetDefault(slog.New(l.SLogHandler())) } }
+//
+// log 包的输出会根据其内容的前缀决定日志的级别，支持 [ERROR]、WARN: 以及 level=debug 等形式，
+// 级别名称不区分大小写，无法识别的内容均以 [LevelInfo] 输出。
+//
+// NOTE: 此操作会清除 log 包的 Prefix 和 Flags 设置，这些功能与当前模块有重叠。
+func WithStd() Option {
+	return func(l *Logs) {
+		slog.SetDefault(slog.New(l.SLogHandler()))
+
+		// slog.SetDefault 也会修改 log 的输出对象，所以必须在其之后调用。
+		log.SetFlags(0)
+		log.SetPrefix("")
+		log.SetOutput(&stdWriter{l: l})
+	}
+}
 
 // WithLevels 指定启用的日志级别
 //
@@ -70,9 +87,73 @@ func WithDetail(v bool) Option { return func(l *Logs) { l.detail = v } }
 // 如果 layout 为空将会禁用日期显示。
 func WithCreated(layout string) Option { return func(l *Logs) { l.createdFormat = layout } }
 
+// WithErrorHandler 指定 [Handler] 在输出日志出错时的处理方式
+//
+// 默认情况下，[NewTextHandler] 和 [NewJSONHandler] 会将错误信息输出到 [os.Stderr]，
+// 而 [NewTermHandler] 则会直接 panic。指定 f 之后，这些错误都将交由 f 处理。
+// 自定义的 [Handler] 也可以通过 [Record.HandleError] 将错误交由 f 处理。
+//
+// f 的参数分别为出错的 [Handler]、输出的日志记录以及错误信息，
+// 其中 [Record] 仅在 f 执行期间有效，不能在 f 返回之后继续持有。
+func WithErrorHandler(f func(Handler, *Record, error)) Option {
+	return func(l *Logs) { l.errHandler = f }
+}
+
+// FallbackErrorHandler 返回一个将出错的日志写入 fallback 的错误处理函数
+//
+// 返回值可作为 [WithErrorHandler] 的参数。当 [Handler] 输出日志出错时，
+// 会将该条日志改由 fallback 输出，之后如果 f 不为 nil，还会调用 f 以便进行报警等操作。
+//
+// NOTE: 由 [Logs.New] 和 [Logger.New] 等方法附加的属性是由原 [Handler] 预先处理的，
+// 并不会出现在 fallback 的输出中。
+func FallbackErrorHandler(fallback Handler, f func(Handler, *Record, error)) func(Handler, *Record, error) {
+	handlers := make(map[Level]Handler, len(levelStrings))
+	for lv := range levelStrings {
+		handlers[lv] = fallback.New(false, lv, nil)
+	}
+
+	return func(h Handler, e *Record, err error) {
+		if hh, found := handlers[e.lv]; found {
+			hh.Handle(e)
+		}
+
+		if f != nil {
+			f(h, e, err)
+		}
+	}
+}
+
 // WithLocation 是否显示定位信息
 func WithLocation(v bool) Option { return func(l *Logs) { l.location = v } }
 
+// WithClock 指定获取日志创建时间的函数
+//
+// 默认为 [time.Now]，也会作用于由 [Logs.SLogHandler] 输出的日志。
+// 可用于在测试中固定时间，以便对输出内容进行逐字节的比较：
+//
+//	t := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+//	l := logs.New(h, logs.WithClock(func() time.Time { return t }))
+func WithClock(f func() time.Time) Option { return func(l *Logs) { l.clock = f } }
+
+// WithCaller 指定处理日志位置信息的函数
+//
+// f 的参数为日志的实际位置，返回值则作为日志的位置信息，
+// 如果返回值的 File 字段为空，表示不记录位置信息。
+// 仅在 [Logs.HasLocation] 为 true 时才会调用。
+// 可用于在测试中固定或是去掉位置信息：
+//
+//	l := logs.New(h, logs.WithLocation(true), logs.WithCaller(func(runtime.Frame) runtime.Frame {
+//	    return runtime.Frame{File: "main.go", Line: 10, Function: "main.main"}
+//	}))
+func WithCaller(f func(runtime.Frame) runtime.Frame) Option { return func(l *Logs) { l.caller = f } }
+
+func (logs *Logs) now() time.Time {
+	if logs.clock != nil {
+		return logs.clock()
+	}
+	return time.Now()
+}
+
 // HasLocation 是否包含定位信息
 func (logs *Logs) HasLocation() bool { return logs.location }
 
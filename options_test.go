@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestWithClock_WithCaller(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)
+	frame := runtime.Frame{File: "main.go", Line: 10, Function: "main.main"}
+	l := New(NewTextHandler(buf),
+		WithCreated(MicroLayout),
+		WithLocation(true),
+		WithClock(func() time.Time { return now }),
+		WithCaller(func(runtime.Frame) runtime.Frame { return frame }),
+	)
+	l.INFO().With("k", "v").String("msg")
+	slog.New(l.SLogHandler()).Warn("slog", "k", 1)
+	a.Equal(buf.String(), "[INFO] 03:04:05.000006 main.go:10\tmsg k=v\n[WARN] 03:04:05.000006 main.go:10\tslog k=1\n")
+
+	// 去掉位置信息
+	buf.Reset()
+	var hasCaller bool
+	l = New(NewTextHandler(buf), WithLocation(true), WithCaller(func(f runtime.Frame) runtime.Frame {
+		hasCaller = f.File != ""
+		return runtime.Frame{}
+	}))
+	l.INFO().String("msg")
+	slog.New(l.SLogHandler()).Info("slog")
+	a.True(hasCaller).Equal(buf.String(), "[INFO] msg\n[INFO] slog\n")
+}
@@ -0,0 +1,355 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/issue9/logs/v7/writers"
+)
+
+const (
+	patternLiteral = iota
+	patternLevel
+	patternTime
+	patternCaller
+	patternFunc
+	patternMessage
+	patternAttr
+	patternAttrs
+)
+
+var patternVerbs = map[string]int{
+	"level":  patternLevel,
+	"time":   patternTime,
+	"caller": patternCaller,
+	"func":   patternFunc,
+	"msg":    patternMessage,
+	"attr":   patternAttr,
+	"attrs":  patternAttrs,
+}
+
+type (
+	patternHandler struct {
+		w        io.Writer
+		mux      sync.Mutex
+		segments []patternSegment
+
+		attrs  []Attr // 由 New 传入的属性
+		text   []byte // 预编译的 attrs，格式与 textHandler 相同。
+		level  string
+		lower  string // 小写形式的 level
+		detail bool
+		raw    bool // 不对控制字符进行转义
+	}
+
+	patternSegment struct {
+		kind  int
+		width int  // 最小宽度，0 表示不限制。
+		left  bool // 是否左对齐
+		arg   string
+		lit   []byte
+	}
+)
+
+// NewPatternHandler 返回将 [Record] 按 layout 指定的格式写入 w 的对象
+//
+// layout 在创建时即被编译，支持以下占位符：
+//   - %level 日志级别，可以通过 %level{lower} 或是 %level{upper} 指定大小写；
+//   - %time 日志的创建时间，即 [Record.Time]，%time{15:04:05} 可以指定格式，
+//     否则采用 [Logs.CreatedFormat]，两者皆为空时采用 [DateMilliLayout]；
+//   - %caller 日志的触发位置，%caller{short} 表示仅输出文件名，%caller{full} 为默认值；
+//   - %func 触发日志的函数名，%func{short} 表示不带包路径；
+//   - %msg 日志的主消息；
+//   - %attr{name} 名称为 name 的属性值，不存在时输出空字符串；
+//   - %attrs 所有属性，以 k=v 的形式输出，以空格分隔；
+//   - %n 换行符；
+//   - %% 表示 % 本身；
+//
+// 所有占位符都可以在 % 之后指定最小宽度，比如 %5level 表示右对齐的宽度为 5 的日志级别，
+// %-5level 则表示左对齐。
+//
+// 如果 layout 不是以换行符结尾，会自动添加换行符。
+// %msg、%attr 和 %attrs 输出内容中的控制字符默认会被转义，可以通过 [DisableSanitize] 禁用。
+// layout 格式错误时会 panic。
+//
+// NOTE: 如果向 w 输出内容时出错，且未通过 [WithErrorHandler] 指定错误处理函数，
+// 会将错误信息输出到终端作为最后的处理方式。
+func NewPatternHandler(layout string, w ...io.Writer) Handler {
+	segments, err := parsePattern(layout)
+	if err != nil {
+		panic(err)
+	}
+
+	return &patternHandler{w: writers.New(w...), segments: segments}
+}
+
+func parsePattern(layout string) ([]patternSegment, error) {
+	segments := make([]patternSegment, 0, 10)
+	lit := make([]byte, 0, len(layout))
+
+	flush := func() {
+		if len(lit) > 0 {
+			segments = append(segments, patternSegment{kind: patternLiteral, lit: lit})
+			lit = make([]byte, 0, len(layout))
+		}
+	}
+
+	for i := 0; i < len(layout); i++ {
+		c := layout[i]
+		if c != '%' {
+			lit = append(lit, c)
+			continue
+		}
+
+		i++
+		if i >= len(layout) {
+			return nil, fmt.Errorf("NewPatternHandler: %s 以单独的 %% 结尾", layout)
+		}
+
+		switch layout[i] {
+		case '%':
+			lit = append(lit, '%')
+			continue
+		case 'n':
+			lit = append(lit, '\n')
+			continue
+		}
+
+		seg := patternSegment{}
+		if layout[i] == '-' {
+			seg.left = true
+			i++
+		}
+		for ; i < len(layout) && layout[i] >= '0' && layout[i] <= '9'; i++ {
+			seg.width = seg.width*10 + int(layout[i]-'0')
+		}
+
+		start := i
+		for ; i < len(layout) && isPatternVerbChar(layout[i]); i++ {
+		}
+		name := layout[start:i]
+		kind, found := patternVerbs[name]
+		if !found {
+			return nil, fmt.Errorf("NewPatternHandler: 无效的占位符 %%%s", name)
+		}
+		seg.kind = kind
+
+		if i < len(layout) && layout[i] == '{' {
+			end := strings.IndexByte(layout[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("NewPatternHandler: %%%s 缺少 }", name)
+			}
+			seg.arg = layout[i+1 : i+end]
+			i += end
+		} else {
+			i--
+		}
+
+		if err := checkPatternArg(name, seg.arg); err != nil {
+			return nil, err
+		}
+
+		flush()
+		segments = append(segments, seg)
+	}
+
+	if len(lit) == 0 || lit[len(lit)-1] != '\n' {
+		lit = append(lit, '\n')
+	}
+	flush()
+
+	return segments, nil
+}
+
+func isPatternVerbChar(c byte) bool { return c >= 'a' && c <= 'z' }
+
+func checkPatternArg(name, arg string) error {
+	var valid bool
+	switch name {
+	case "level":
+		valid = arg == "" || arg == "lower" || arg == "upper"
+	case "caller", "func":
+		valid = arg == "" || arg == "short" || arg == "full"
+	case "attr":
+		valid = arg != ""
+	case "time":
+		valid = true
+	default:
+		valid = arg == ""
+	}
+
+	if !valid {
+		return fmt.Errorf("NewPatternHandler: %%%s 的参数 %s 无效", name, arg)
+	}
+	return nil
+}
+
+func (h *patternHandler) Handle(e *Record) {
+	if err := h.handle(e); err != nil && !e.HandleError(h, err) {
+		fmt.Fprintf(os.Stderr, "NewPatternHandler.Handle:%v\n", err)
+	}
+}
+
+func (h *patternHandler) handle(e *Record) error {
+	b := NewBuffer(h.detail)
+	defer b.Free()
+
+	for _, seg := range h.segments {
+		if seg.kind == patternLiteral {
+			b.AppendBytes(seg.lit...)
+			continue
+		}
+
+		start := b.Len()
+		h.appendSegment(b, e, &seg)
+		if seg.width > 0 {
+			padPattern(b, start, seg.width, seg.left)
+		}
+	}
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+func (h *patternHandler) appendSegment(b *Buffer, e *Record, seg *patternSegment) {
+	switch seg.kind {
+	case patternLevel:
+		switch seg.arg {
+		case "lower":
+			b.AppendString(h.lower)
+		default:
+			b.AppendString(h.level)
+		}
+	case patternTime:
+		switch {
+		case seg.arg != "":
+			b.AppendTime(e.Time(), seg.arg)
+		case e.AppendCreated != nil:
+			b.AppendFunc(e.AppendCreated)
+		default:
+			b.AppendTime(e.Time(), DateMilliLayout)
+		}
+	case patternCaller:
+		if e.AppendLocation == nil {
+			return
+		}
+		start := b.Len()
+		b.AppendFunc(e.AppendLocation)
+		if seg.arg == "short" {
+			trimPatternPath(b, start)
+		}
+	case patternFunc:
+		start := b.Len()
+		b.AppendString(e.Caller().Function)
+		if seg.arg == "short" {
+			trimPatternPath(b, start)
+		}
+	case patternMessage:
+		switch {
+		case e.AppendMessage == nil:
+		case h.raw:
+			b.AppendFunc(e.AppendMessage)
+		default:
+			appendSanitizedMessage(b, e)
+		}
+	case patternAttr:
+		if v, found := h.lookup(e, seg.arg); found {
+			start := b.Len()
+			appendTextValue(b, v)
+			if !h.raw {
+				sanitize(b, start)
+			}
+		}
+	case patternAttrs:
+		start := b.Len()
+		b.AppendBytes(h.text...)
+		th := &textHandler{raw: h.raw}
+		for _, p := range e.Attrs {
+			th.buildAttr(b, p)
+		}
+		if b.Len() > start { // 去掉第一个属性之前的空格
+			b.data = append(b.data[:start], b.data[start+1:]...)
+		}
+	}
+}
+
+// 查找名称为 name 的属性，后添加的优先。
+func (h *patternHandler) lookup(e *Record, name string) (any, bool) {
+	for i := len(e.Attrs) - 1; i >= 0; i-- {
+		if e.Attrs[i].K == name {
+			return e.Attrs[i].V, true
+		}
+	}
+	for i := len(h.attrs) - 1; i >= 0; i-- {
+		if h.attrs[i].K == name {
+			return h.attrs[i].V, true
+		}
+	}
+	return nil, false
+}
+
+// 只保留 b 中从 start 开始的内容中最后一个 / 之后的部分
+func trimPatternPath(b *Buffer, start int) {
+	if index := bytes.LastIndexByte(b.data[start:], '/'); index >= 0 {
+		b.data = append(b.data[:start], b.data[start+index+1:]...)
+	}
+}
+
+// 将 b 中从 start 开始的内容以空格填充至 width 个字符
+func padPattern(b *Buffer, start, width int, left bool) {
+	n := width - utf8.RuneCount(b.data[start:])
+	if n <= 0 {
+		return
+	}
+
+	if left {
+		for range n {
+			b.data = append(b.data, ' ')
+		}
+		return
+	}
+
+	size := b.Len()
+	for range n {
+		b.data = append(b.data, ' ')
+	}
+	copy(b.data[start+n:], b.data[start:size])
+	for i := start; i < start+n; i++ {
+		b.data[i] = ' '
+	}
+}
+
+func (h *patternHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	b := NewBuffer(false)
+	defer b.Free()
+	(&textHandler{raw: h.raw}).buildAttrs(b, attrs)
+
+	text := make([]byte, 0, b.Len()+len(h.text))
+	text = append(text, h.text...)
+
+	as := make([]Attr, 0, len(h.attrs)+len(attrs))
+	as = append(as, h.attrs...)
+
+	return &patternHandler{
+		w:        h.w,
+		segments: h.segments,
+
+		attrs:  append(as, attrs...),
+		text:   append(text, b.Bytes()...),
+		level:  lv.String(),
+		lower:  strings.ToLower(lv.String()),
+		detail: detail,
+		raw:    h.raw,
+	}
+}
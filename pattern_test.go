@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestParsePattern(t *testing.T) {
+	a := assert.New(t, false)
+
+	segs, err := parsePattern("%-5level %msg%n")
+	a.NotError(err).Length(segs, 4).
+		Equal(segs[0], patternSegment{kind: patternLevel, width: 5, left: true}).
+		Equal(segs[1], patternSegment{kind: patternLiteral, lit: []byte(" ")}).
+		Equal(segs[2], patternSegment{kind: patternMessage}).
+		Equal(segs[3], patternSegment{kind: patternLiteral, lit: []byte("\n")})
+
+	segs, err = parsePattern("100%% %attr{uid}")
+	a.NotError(err).Length(segs, 3).
+		Equal(segs[0], patternSegment{kind: patternLiteral, lit: []byte("100% ")}).
+		Equal(segs[1], patternSegment{kind: patternAttr, arg: "uid"}).
+		Equal(segs[2], patternSegment{kind: patternLiteral, lit: []byte("\n")})
+
+	_, err = parsePattern("%msg %")
+	a.Error(err)
+
+	_, err = parsePattern("%unknown")
+	a.Error(err)
+
+	_, err = parsePattern("%time{15:04")
+	a.Error(err)
+
+	_, err = parsePattern("%level{camel}")
+	a.Error(err)
+
+	_, err = parsePattern("%attr")
+	a.Error(err)
+
+	_, err = parsePattern("%msg{x}")
+	a.Error(err)
+
+	a.PanicString(func() { NewPatternHandler("%xx") }, "NewPatternHandler: 无效的占位符 %xx")
+}
+
+func TestPatternHandler(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	now := time.Date(2026, 1, 2, 15, 4, 5, 6_000_000, time.UTC)
+
+	h := NewPatternHandler("%time{15:04:05.000} %-5level [%caller{short}] %msg %attrs%n", buf)
+	l := New(h, WithLocation(true))
+	e := newRecord(a, l)
+	e.AppendLocation = func(b *Buffer) { b.AppendString("/src/path.go:20") }
+	e.created = now
+	h = h.New(false, LevelWarn, []Attr{{K: "a1", V: 5}})
+	h.Handle(e)
+	a.Equal(buf.String(), "15:04:05.006 WARN  [path.go:20] msg a1=5 k1=v1 k2=v2\n")
+
+	// 未指定换行符、右对齐以及小写
+	buf.Reset()
+	h = NewPatternHandler("%6level{lower}|%caller|%msg|%attr{k2}|%attr{a1}|%attr{not-exists}|%attrs", buf)
+	e = newRecord(a, l)
+	h = h.New(false, LevelInfo, []Attr{{K: "a1", V: "x"}}).New(false, LevelError, []Attr{{K: "a1", V: 1.5}})
+	h.Handle(e)
+	a.Equal(buf.String(), "  erro|path.go:20|msg|v2|1.5||a1=x a1=1.5 k1=v1 k2=v2\n")
+
+	// 没有属性
+	buf.Reset()
+	e = newRecord(a, l)
+	e.Attrs = nil
+	NewPatternHandler("%msg[%attrs]", buf).New(false, LevelInfo, nil).Handle(e)
+	a.Equal(buf.String(), "msg[]\n")
+
+	// 通过 Logs 输出
+	buf.Reset()
+	l = New(NewPatternHandler("%level %caller{short} %func{short} %msg", buf), WithLocation(true))
+	l.INFO().String("abc")
+	a.Equal(buf.String(), "INFO pattern_test.go:84 v7.TestPatternHandler abc\n")
+
+	// %time 采用 Logs.CreatedFormat
+	buf.Reset()
+	l = New(NewPatternHandler("%time %msg", buf), WithCreated("2006"))
+	l.INFO().String("abc")
+	a.Equal(buf.String(), time.Now().Format("2006")+" abc\n")
+
+	// %time 采用 WithClock 指定的时间
+	buf.Reset()
+	clock := WithClock(func() time.Time { return now })
+	l = New(NewPatternHandler("%time %time{15:04} %msg", buf), clock)
+	l.INFO().String("abc")
+	l = New(NewPatternHandler("%time %msg", buf), clock, WithCreated(MilliLayout))
+	l.INFO().String("abc")
+	a.Equal(buf.String(), "2026-01-02T15:04:05.006 15:04 abc\n15:04:05.006 abc\n")
+}
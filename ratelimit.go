@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// 按分组限制时最多保留的令牌桶数量，超出时删除最久未使用的令牌桶。
+const maxRateBuckets = 1024
+
+type (
+	// RateLimit 令牌桶的参数
+	RateLimit struct {
+		// Rate 每秒生成的令牌数量
+		Rate float64
+
+		// Burst 令牌桶的容量
+		Burst int
+	}
+
+	// RateLimitOptions [NewRateLimitHandler] 的配置项
+	RateLimitOptions struct {
+		// Levels 各级别的限制
+		//
+		// 未指定的级别不作限制。
+		Levels map[Level]RateLimit
+
+		// Key 对日志进行分组的函数
+		//
+		// 返回相同值的日志共用一个由 KeyLimit 指定参数的令牌桶，为空表示不启用该功能。
+		// 最多保留 1024 个分组的令牌桶，超出时会删除最久未使用的令牌桶。
+		Key func(*Record) string
+
+		// KeyLimit 每个分组的限制
+		KeyLimit RateLimit
+	}
+
+	rateLimitHandler struct {
+		h  Handler
+		lv Level
+		r  *rateLimiter
+	}
+
+	rateLimiter struct {
+		mux      sync.Mutex
+		levels   map[Level]*tokenBucket
+		key      func(*Record) string
+		keyLimit RateLimit
+		keys     map[string]*list.Element // 值为 *keyBucket
+		lru      *list.List               // 按使用时间排序的令牌桶，最近使用的在最前。
+	}
+
+	keyBucket struct {
+		key string
+		b   *tokenBucket
+	}
+
+	tokenBucket struct {
+		limit  RateLimit
+		tokens float64
+		last   time.Time
+	}
+)
+
+// NewRateLimitHandler 返回以令牌桶算法限制日志输出频率的 [Handler]
+//
+// 超出限制的日志会被直接丢弃。
+//
+// o 可以为 nil，表示不作任何限制。
+func NewRateLimitHandler(h Handler, o *RateLimitOptions) Handler {
+	if o == nil {
+		o = &RateLimitOptions{}
+	}
+
+	r := &rateLimiter{
+		levels:   make(map[Level]*tokenBucket, len(o.Levels)),
+		key:      o.Key,
+		keyLimit: o.KeyLimit,
+		keys:     make(map[string]*list.Element, 10),
+		lru:      list.New(),
+	}
+	for lv, l := range o.Levels {
+		r.levels[lv] = newTokenBucket(l)
+	}
+
+	return &rateLimitHandler{h: h, r: r}
+}
+
+func (h *rateLimitHandler) Handle(e *Record) {
+	if h.r.allow(h.lv, e) {
+		h.h.Handle(e)
+	}
+}
+
+func (h *rateLimitHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	return &rateLimitHandler{h: h.h.New(detail, lv, attrs), lv: lv, r: h.r}
+}
+
+func (r *rateLimiter) allow(lv Level, e *Record) bool {
+	var key string
+	if r.key != nil {
+		key = r.key(e)
+	}
+
+	now := time.Now()
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	var kb *tokenBucket
+	if r.key != nil {
+		kb = r.keyBucket(key)
+		if kb.fill(now) < 1 {
+			return false
+		}
+	}
+
+	// 两个令牌桶都有令牌时才消耗令牌
+	lb := r.levels[lv]
+	if lb != nil && lb.fill(now) < 1 {
+		return false
+	}
+
+	if kb != nil {
+		kb.tokens--
+	}
+	if lb != nil {
+		lb.tokens--
+	}
+	return true
+}
+
+// 获取 key 对应的令牌桶，不存在则创建。
+func (r *rateLimiter) keyBucket(key string) *tokenBucket {
+	if elem, found := r.keys[key]; found {
+		r.lru.MoveToFront(elem)
+		return elem.Value.(*keyBucket).b
+	}
+
+	if r.lru.Len() >= maxRateBuckets {
+		elem := r.lru.Back()
+		r.lru.Remove(elem)
+		delete(r.keys, elem.Value.(*keyBucket).key)
+	}
+
+	b := newTokenBucket(r.keyLimit)
+	r.keys[key] = r.lru.PushFront(&keyBucket{key: key, b: b})
+	return b
+}
+
+func newTokenBucket(l RateLimit) *tokenBucket {
+	return &tokenBucket{limit: l, tokens: float64(l.Burst), last: time.Now()}
+}
+
+func (b *tokenBucket) fill(now time.Time) float64 {
+	b.tokens = min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	b.last = now
+	return b.tokens
+}
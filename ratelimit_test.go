@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	l := New(NewRateLimitHandler(NewTextHandler(buf), &RateLimitOptions{
+		Levels: map[Level]RateLimit{LevelDebug: {Rate: 0.001, Burst: 3}},
+	}))
+	for range 10 {
+		l.DEBUG().String("debug")
+		l.INFO().String("info")
+	}
+	a.Equal(strings.Count(buf.String(), "[DBUG] debug\n"), 3).
+		Equal(strings.Count(buf.String(), "[INFO] info\n"), 10)
+
+	// 按 key 限制
+	buf.Reset()
+	l = New(NewRateLimitHandler(NewTextHandler(buf), &RateLimitOptions{
+		Levels:   map[Level]RateLimit{LevelInfo: {Rate: 0.001, Burst: 5}},
+		Key:      func(r *Record) string { return r.Message() },
+		KeyLimit: RateLimit{Rate: 0.001, Burst: 2},
+	}))
+	for range 10 {
+		l.INFO().String("k1")
+		l.INFO().String("k2")
+		l.WARN().String("k3")
+	}
+	a.Equal(strings.Count(buf.String(), "[INFO] k1\n"), 2).
+		Equal(strings.Count(buf.String(), "[INFO] k2\n"), 2).
+		Equal(strings.Count(buf.String(), "[WARN] k3\n"), 2)
+
+	// 令牌的恢复
+	buf.Reset()
+	l = New(NewRateLimitHandler(NewTextHandler(buf), &RateLimitOptions{
+		Levels: map[Level]RateLimit{LevelInfo: {Rate: 20, Burst: 1}},
+	}))
+	l.INFO().String("1")
+	l.INFO().String("2")
+	time.Sleep(100 * time.Millisecond)
+	l.INFO().String("3")
+	a.Equal(buf.String(), "[INFO] 1\n[INFO] 3\n")
+
+	// 被级别限制的日志不会消耗分组的令牌
+	buf.Reset()
+	l = New(NewRateLimitHandler(NewTextHandler(buf), &RateLimitOptions{
+		Levels:   map[Level]RateLimit{LevelInfo: {Rate: 20, Burst: 1}},
+		Key:      func(r *Record) string { return r.Message() },
+		KeyLimit: RateLimit{Rate: 0.001, Burst: 2},
+	}))
+	l.INFO().String("k1")
+	l.INFO().String("k1")
+	l.INFO().String("k1")
+	time.Sleep(100 * time.Millisecond)
+	l.INFO().String("k1")
+	a.Equal(buf.String(), "[INFO] k1\n[INFO] k1\n")
+}
+
+func TestRateLimitHandler_lru(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	h := NewRateLimitHandler(NewTextHandler(buf), &RateLimitOptions{
+		Key:      func(r *Record) string { return r.Message() },
+		KeyLimit: RateLimit{Rate: 0.001, Burst: 1},
+	})
+	l := New(h)
+	r := h.(*rateLimitHandler).r
+
+	l.INFO().String("first")
+	for i := range maxRateBuckets * 2 {
+		l.INFO().String(strconv.Itoa(i))
+		l.INFO().String("first") // 保持 first 为最近使用的
+	}
+	a.Equal(len(r.keys), maxRateBuckets).
+		Equal(r.lru.Len(), maxRateBuckets).
+		Equal(strings.Count(buf.String(), "[INFO] first\n"), 1)
+}
+
+func TestRateLimitHandler_nil(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	l := New(NewRateLimitHandler(NewTextHandler(buf), nil))
+	for range 3 {
+		l.ERROR().String("msg")
+	}
+	a.Equal(buf.String(), strings.Repeat("[ERRO] msg\n", 3))
+}
@@ -6,6 +6,7 @@ package logs
 
 import (
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -78,7 +79,17 @@ type (
 	//
 	// NOTE: 该对象只能由 [Logs.NewRecord] 生成。
 	Record struct {
-		logs *Logs
+		logs        *Logs
+		lv          Level
+		handlingErr bool // 是否正在处理 HandleError，防止 fallback 出错造成死循环。
+		created     time.Time
+		pc          uintptr // 返回地址，与 slog.Record.PC 相同。
+		frame       runtime.Frame
+		err         error
+		template    string // 消息的模板，即 DepthPrintf 的 format 或是 DepthString 的参数。
+		frozen      bool   // 由 Freeze 生成的对象，不能放回对象池。
+		leveled     bool   // lv 是否在创建时就已经确定，由 Logger 创建的对象在创建时即可确定级别。
+		stack       []uintptr
 
 		// AppendCreated 添加字符串类型的日志创建时间
 		//
@@ -92,7 +103,8 @@ type (
 
 		// AppendLocation 添加字符串类型的日志触发位置信息
 		//
-		// 可能为空，根据 [Logs.HasLocation] 决定。
+		// 可能为空，根据 [Logs.HasLocation] 决定，格式由 [WithLocationFormat] 决定。
+		// 如果需要分别获取文件、行号和函数名等信息，可以使用 [Record.Caller]。
 		AppendLocation AppendFunc
 
 		// 额外的数据，比如由 [Recorder.With] 添加的数据。
@@ -122,6 +134,14 @@ func (logs *Logs) NewRecord() *Record {
 	e.AppendMessage = nil
 	e.AppendCreated = nil
 	e.logs = logs
+	e.handlingErr = false
+	e.created = time.Time{}
+	e.pc = 0
+	e.frame = runtime.Frame{}
+	e.err = nil
+	e.template = ""
+	e.leveled = false
+	e.stack = nil
 
 	return e
 }
@@ -129,21 +149,46 @@ func (logs *Logs) NewRecord() *Record {
 // depth 表示调用，1 表示调用此方法的位置；
 func (e *Record) initLocationCreated(depth int) *Record {
 	if e.logs.HasLocation() {
-		_, p, l, _ := runtime.Caller(depth)
-		e.AppendLocation = func(b *Buffer) {
-			b.AppendString(p).AppendBytes(':').AppendInt(int64(l), 10)
-		}
+		var pcs [1]uintptr
+		runtime.Callers(depth+1, pcs[:]) // 与 slog.Record.PC 保持一致，保存的是返回地址。
+		e.setLocation(pcs[0])
+	}
+
+	if e.leveled {
+		e.captureStack(depth + 1)
 	}
 
+	t := e.logs.now() // 必须是当前时间，而不是放在 AppendCreated 中获取的时间。
+	e.created = t
 	if e.logs.createdFormat != "" {
-		t := time.Now() // 必须是当前时间，而不是放在 AppendCreated 中获取的时间。
 		e.AppendCreated = func(b *Buffer) { b.AppendTime(t, e.logs.createdFormat) }
 	}
 
 	return e
 }
 
-func (e *Record) with(name string, val any) *Record {
+// 根据 pc 设置位置信息
+//
+// pc 为 [runtime.Callers] 返回的地址，如果指定了 [WithCaller]，则以其返回值作为位置信息。
+func (e *Record) setLocation(pc uintptr) {
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if e.logs.caller != nil {
+		if f = e.logs.caller(f); f.File == "" { // 不记录位置信息
+			return
+		}
+	}
+
+	e.pc = pc
+	e.frame = f
+	format := e.logs.locationFormat
+	e.AppendLocation = func(b *Buffer) { format.append(b, f) }
+}
+
+// With 为日志添加属性
+//
+// 如果 val 实现了 [localeutil.Stringer] 或是 [Marshaler] 接口，
+// 将被转换成字符串保存。
+func (e *Record) With(name string, val any) *Record {
 	switch v := val.(type) {
 	case localeutil.Stringer:
 		e.Attrs = append(e.Attrs, Attr{K: name, V: v.LocaleString(e.logs.printer)})
@@ -164,21 +209,27 @@ func (e *Record) DepthError(depth int, err error) *Record {
 	if err == nil {
 		panic("参数 err 不能为空")
 	}
+	e.err = err
+	e.AppendMessage = errorAppendFunc(e.logs.printer, err)
+	return e.initLocationCreated(depth)
+}
 
+// 生成输出 err 的 [AppendFunc]
+//
+// 返回的函数不会引用 [Record]，可以在 [Record.Freeze] 中复用。
+func errorAppendFunc(p *localeutil.Printer, err error) AppendFunc {
 	switch ee := err.(type) {
 	case xerrors.Formatter:
-		e.AppendMessage = func(b *Buffer) { appendError(e.logs.printer, b, ee) }
+		return func(b *Buffer) { appendError(p, b, ee, false) }
 	case localeutil.Stringer:
-		if pp := e.logs.printer; pp != nil {
-			e.AppendMessage = func(b *Buffer) { b.AppendString(ee.LocaleString(pp)) }
-		} else { // ee 必然是实现了 error 接口的
-			e.AppendMessage = func(b *Buffer) { b.AppendString(err.Error()) }
+		if p != nil {
+			return func(b *Buffer) { b.AppendString(ee.LocaleString(p)) }
 		}
+		// ee 必然是实现了 error 接口的
+		return func(b *Buffer) { b.AppendString(err.Error()) }
 	default:
-		e.AppendMessage = func(b *Buffer) { b.AppendString(err.Error()) }
+		return func(b *Buffer) { b.AppendString(err.Error()) }
 	}
-
-	return e.initLocationCreated(depth)
 }
 
 // DepthLocaleString 输出 [localeutil.Stringer]  类型的内容到日志
@@ -191,21 +242,35 @@ func (e *Record) DepthLocaleString(depth int, s localeutil.Stringer) *Record {
 	return e.initLocationCreated(depth)
 }
 
-func appendError(p *localeutil.Printer, b *Buffer, ef xerrors.Formatter) {
-	err := ef.FormatError(b)
+// 输出 ef 及其错误链中的所有错误
+//
+// sanitize 表示是否需要转义错误信息中的控制字符。
+func appendError(p *localeutil.Printer, b *Buffer, ef xerrors.Formatter, sanitize bool) {
+	var w xerrors.Printer = b
+	var sp *sanitizePrinter
+	if sanitize {
+		sp = &sanitizePrinter{b: b}
+		w = sp
+	}
+
+	err := ef.FormatError(w)
 	for err != nil {
+		if sp != nil {
+			sp.detail = false
+		}
+
 		switch e2 := err.(type) {
 		case xerrors.Formatter:
-			err = e2.FormatError(b)
+			err = e2.FormatError(w)
 		case localeutil.Stringer:
 			if p != nil {
-				b.AppendString(e2.LocaleString(p))
+				w.Print(e2.LocaleString(p))
 			} else { // e2 必然是实现了 error 接口的
-				b.AppendString(e2.(error).Error())
+				w.Print(e2.(error).Error())
 			}
 			return
 		default:
-			b.AppendString(e2.Error())
+			w.Print(e2.Error())
 			return
 		}
 	}
@@ -217,6 +282,7 @@ func appendError(p *localeutil.Printer, b *Buffer, ef xerrors.Formatter) {
 //
 // 如果 [Logs.HasLocation] 为 false，那么 depth 将不起实际作用。
 func (e *Record) DepthString(depth int, s string) *Record {
+	e.template = s
 	e.AppendMessage = func(b *Buffer) { b.AppendString(s) }
 	return e.initLocationCreated(depth)
 }
@@ -242,15 +308,173 @@ func (e *Record) DepthPrint(depth int, v ...any) *Record {
 // 如果需要翻译内容，可以调用 [Record.DepthLocaleString]。
 func (e *Record) DepthPrintf(depth int, format string, v ...any) *Record {
 	replaceLocaleString(e.logs.printer, v)
+	e.template = format
 	e.AppendMessage = func(b *Buffer) { b.Appendf(format, v...) }
 	return e.initLocationCreated(depth)
 }
 
+// Time 日志的创建时间
+//
+// 无论 [Logs.CreatedFormat] 是否为空都会记录时间，
+// [Logs.CreatedFormat] 仅决定 [Record.AppendCreated] 是否为空。
+func (e *Record) Time() time.Time { return e.created }
+
+// Level 日志的级别
+//
+// 由 [Logger] 创建的对象在创建时即已确定级别，
+// 由 [Logs.NewRecord] 创建的对象则在 [Record.Output] 中根据其参数设置。
+func (e *Record) Level() Level { return e.lv }
+
+// Err 由 [Record.DepthError] 输出的错误对象
+//
+// 如果日志并不是由 [Record.DepthError] 输出的，则返回 nil。
+func (e *Record) Err() error { return e.err }
+
+// Caller 日志的触发位置
+//
+// 只有在 [Logs.HasLocation] 为 true 时才会记录位置信息，否则返回零值。
+// 如果指定了 [WithCaller]，则返回的是经其处理之后的值。
+// 返回值的 PC 字段与 [runtime.Frame] 的约定相同，表示的是调用指令的地址。
+func (e *Record) Caller() runtime.Frame { return e.frame }
+
+// PC 日志触发位置的返回地址
+//
+// 与 slog.Record.PC 相同，可以通过 [runtime.CallersFrames] 获取位置信息。
+// 只有在 [Logs.HasLocation] 为 true 时才会记录，否则返回零值。
+func (e *Record) PC() uintptr { return e.pc }
+
+// Message 以字符串的形式返回日志的主消息
+//
+// 每次调用都会重新生成字符串，不需要字符串时，应该优先使用 [Record.AppendMessage]。
+func (e *Record) Message() string {
+	if e.AppendMessage == nil {
+		return ""
+	}
+
+	b := NewBuffer(false)
+	defer b.Free()
+	b.AppendFunc(e.AppendMessage)
+	return string(b.Bytes())
+}
+
+// HandleError 将 h 在输出 e 时产生的错误 err 交由 [WithErrorHandler] 指定的函数处理
+//
+// 如果未指定处理函数，或是当前已经处于错误处理的过程中，则返回 false，
+// 此时应该由 h 自行决定如何处理该错误。
+func (e *Record) HandleError(h Handler, err error) bool {
+	if e.logs.errHandler == nil || e.handlingErr {
+		return false
+	}
+
+	// e 可能是由多个 goroutine 同时输出的快照，不能修改其字段，
+	// 所以在副本上标记正在处理错误的状态。
+	r := *e
+	r.handlingErr = true
+	e.logs.errHandler(h, &r, err)
+	return true
+}
+
+// Freeze 生成当前记录的快照
+//
+// [Record] 在 [Record.Output] 之后会被回收复用，且 [Record.AppendMessage] 等可能引用了调用方的参数，
+// 所以 [Handler] 不能在 [Handler.Handle] 返回之后继续持有 [Record]。
+// 如果需要异步处理或是缓存日志，可以通过此方法生成一个不会被回收的副本：
+// 创建时间、位置信息和消息会被提前生成，[Record.Attrs] 会被复制，
+// 其中类型为 []Attr 和 []byte 的值会被深度复制，其它引用类型的值则依然是共享的。
+//
+// 快照可能被多个 [Handler] 或是 goroutine 共享，不能修改其任何字段，
+// 如果需要修改，应该先复制一份。
+// 如果当前对象已经是快照，则直接返回当前对象。
+func (e *Record) Freeze() *Record {
+	if e.frozen {
+		return e
+	}
+
+	r := &Record{
+		logs:     e.logs,
+		lv:       e.lv,
+		created:  e.created,
+		pc:       e.pc,
+		frame:    e.frame,
+		err:      e.err,
+		template: e.template,
+		frozen:   true,
+		leveled:  e.leveled,
+		stack:    e.stack,
+		Attrs:    cloneAttrs(e.Attrs),
+	}
+
+	if e.AppendCreated != nil {
+		r.AppendCreated = appendStringFunc(e.AppendCreated)
+	}
+	if e.AppendLocation != nil {
+		r.AppendLocation = appendStringFunc(e.AppendLocation)
+	}
+
+	if e.err != nil { // 错误信息的输出内容与 Buffer.Detail 相关，不能提前生成。
+		r.AppendMessage = errorAppendFunc(e.logs.printer, e.err)
+	} else if e.AppendMessage != nil {
+		r.AppendMessage = appendStringFunc(e.AppendMessage)
+	}
+
+	return r
+}
+
+// 生成一个可以由调用方修改的快照
+//
+// 与 [Record.Freeze] 不同，即使 e 已经是快照，也会返回一个新的对象。
+func (e *Record) snapshot() *Record {
+	if !e.frozen {
+		return e.Freeze()
+	}
+
+	r := *e
+	r.Attrs = cloneAttrs(e.Attrs)
+	return &r
+}
+
+// 执行 f 并将其结果转换为固定内容的 [AppendFunc]
+func appendStringFunc(f AppendFunc) AppendFunc {
+	b := NewBuffer(false)
+	defer b.Free()
+	f(b)
+	s := string(b.Bytes())
+
+	return func(b *Buffer) { b.AppendString(s) }
+}
+
+func cloneAttrs(attrs []Attr) []Attr {
+	if attrs == nil {
+		return nil
+	}
+
+	as := make([]Attr, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.V.(type) {
+		case []Attr:
+			a.V = cloneAttrs(v)
+		case []byte:
+			a.V = slices.Clone(v)
+		}
+		as = append(as, a)
+	}
+	return as
+}
+
 // Output 输出当前记录到日志
+//
+// 由 [Record.Freeze] 生成的对象也可以调用此方法，但不会被回收，
+// 且依然保持原来的级别，可以在多个 goroutine 中同时输出同一个快照。
 func (e *Record) Output(l *Logger) {
 	const poolMaxAttrs = 100
+	if !e.frozen {
+		e.lv = l.Level()
+		if !e.leveled { // 由 Logs.NewRecord 创建的对象，只能以调用 Output 的位置作为堆栈的起点。
+			e.captureStack(2)
+		}
+	}
 	l.Handler().Handle(e)
-	if len(e.Attrs) < poolMaxAttrs {
+	if !e.frozen && len(e.Attrs) < poolMaxAttrs {
 		recordPool.Put(e)
 	}
 }
@@ -268,7 +492,7 @@ func replaceLocaleString(p *localeutil.Printer, v []any) {
 }
 
 func (e *withRecorder) With(name string, val any) Recorder {
-	e.r.with(name, val)
+	e.r.With(name, val)
 	return e
 }
 
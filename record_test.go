@@ -137,3 +137,89 @@ func TestRecord_Error(t *testing.T) {
 		a.True(strings.HasSuffix(buf.String(), "root\nroot\ncn\n"), buf.String())
 	})
 }
+
+type handleFunc func(*Record)
+
+func (f handleFunc) Handle(e *Record) { f(e) }
+
+func (f handleFunc) New(bool, Level, []Attr) Handler { return f }
+
+func TestRecord_accessors(t *testing.T) {
+	a := assert.New(t, false)
+
+	var (
+		hasTime bool
+		lv      Level
+		e       error
+		file    string
+		line    int
+		fn      string
+		msg     string
+	)
+	h := handleFunc(func(r *Record) {
+		hasTime = !r.Time().IsZero()
+		lv = r.Level()
+		e = r.Err()
+		file, line, fn = r.Caller().File, r.Caller().Line, r.Caller().Function
+		msg = r.Message()
+	})
+
+	l := New(h, WithLocation(true), WithCreated(MicroLayout))
+	err1 := errors.New("error")
+	l.WARN().Error(err1)
+	a.True(hasTime).
+		Equal(lv, LevelWarn).
+		Equal(e, err1).
+		True(strings.HasSuffix(file, "record_test.go")).
+		Equal(line, 169).
+		Equal(fn, "github.com/issue9/logs/v7.TestRecord_accessors").
+		Equal(msg, "error")
+
+	l = New(h) // 未指定 WithCreated 也会记录时间
+	l.ERROR().Printf("%d", 5)
+	a.True(hasTime).
+		Equal(lv, LevelError).
+		Nil(e).
+		Empty(file).
+		Equal(msg, "5")
+}
+
+func TestRecord_Freeze(t *testing.T) {
+	a := assert.New(t, false)
+
+	var frozen []*Record
+	h := handleFunc(func(r *Record) { frozen = append(frozen, r.Freeze()) })
+	l := New(h, WithLocation(true), WithCreated(MicroLayout))
+
+	args := []any{"v1"}
+	bs := []byte("123")
+	l.WARN().With("bytes", bs).With("group", []Attr{{K: "k", V: "v"}}).Print(args...)
+	args[0] = "v2"
+	bs[0] = '0'
+	l.ERROR().Error(&err{err: errors.New("error")})
+	a.Length(frozen, 2)
+
+	f := frozen[0]
+	a.Equal(f.Freeze(), f).
+		Equal(f.Level(), LevelWarn).
+		Equal(f.Message(), "v1").
+		Equal(f.Attrs[0].V, []byte("123")).
+		Equal(f.Attrs[1].V, []Attr{{K: "k", V: "v"}})
+
+	buf := new(bytes.Buffer)
+	NewTextHandler(buf).New(false, f.Level(), nil).Handle(f)
+	a.Contains(buf.String(), "record_test.go:").
+		Contains(buf.String(), "\tv1 bytes=[49 50 51] group.k=v\n")
+
+	// 快照不会被回收，也不会被修改。
+	f.Output(l.INFO())
+	a.NotEqual(l.NewRecord(), f).Equal(f.Level(), LevelWarn)
+
+	// 错误信息依然与 Buffer.Detail 相关
+	f = frozen[1]
+	b := NewBuffer(true)
+	defer b.Free()
+	f.AppendMessage(b)
+	a.Equal(string(b.Bytes()), "root\nerror").
+		Equal(f.Message(), "root\n")
+}
@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 敏感信息的处理方式
+const (
+	RedactRemove  RedactMode = iota // 删除整个属性
+	RedactMask                      // 替换为 ***
+	RedactPartial                   // 仅保留最后几个字符，比如 ****1234
+	RedactHash                      // 替换为 HMAC-SHA256 的值，相同的值始终会得到相同的结果
+)
+
+// 常用的敏感信息的值的匹配规则
+var (
+	RedactEmail      = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	RedactCreditCard = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	RedactJWT        = regexp.MustCompile(`eyJ[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]*`)
+)
+
+const partialMask = "****"
+
+type (
+	// RedactMode 敏感信息的处理方式
+	RedactMode int8
+
+	// RedactRule 敏感信息的匹配规则
+	//
+	// Key 和 KeyRegexp 用于匹配属性的名称，都为空表示匹配所有属性；
+	// Value 用于匹配属性的值，为空表示处理整个值，否则只处理值中与 Value 匹配的部分。
+	RedactRule struct {
+		// Key 属性名称
+		//
+		// 不区分大小写，可以是 [path.Match] 支持的通配符，比如 *_token。
+		Key string
+
+		// KeyRegexp 匹配属性名称的正则表达式
+		KeyRegexp *regexp.Regexp
+
+		// Value 匹配属性值的正则表达式
+		//
+		// 仅对字符串、[]byte、error 和 [fmt.Stringer] 类型的值有效，
+		// 比如 [RedactEmail]、[RedactCreditCard] 和 [RedactJWT]。
+		// 在 Mode 为 [RedactRemove] 时，只要值中包含匹配的内容即删除整个属性。
+		Value *regexp.Regexp
+
+		// Mode 处理方式
+		Mode RedactMode
+	}
+
+	// RedactOptions [NewRedactHandler] 的配置项
+	RedactOptions struct {
+		// Rules 匹配规则
+		//
+		// 按顺序匹配，以第一个匹配的规则为准。
+		Rules []RedactRule
+
+		// HashKey 计算 HMAC 的密钥
+		//
+		// 如果 Rules 中包含 [RedactHash]，则不能为空。
+		HashKey []byte
+
+		// Reveal [RedactPartial] 保留的字符数量
+		//
+		// 默认值为 4。
+		Reveal int
+	}
+
+	redactHandler struct {
+		h Handler
+		r *redactor
+	}
+
+	redactor struct {
+		rules   []RedactRule
+		hashKey []byte
+		reveal  int
+	}
+)
+
+// NewRedactHandler 返回隐藏敏感信息的 [Handler]
+//
+// 返回对象会在将日志交给 h 之前，根据 o 中的规则处理 [Record.Attrs] 以及 [Handler.New] 中的属性，
+// 所以通过 [Logs.New] 和 [Logger.New] 等方法添加的属性同样会被处理。
+// 处理过程不会修改原始的属性。
+//
+// o 可以为 nil，表示不作任何处理。
+//
+// NOTE: 由 [Recorder.With] 添加的属性会在 [Handler.Handle] 中处理，
+// 不包含敏感信息时不会产生额外的内存分配。
+func NewRedactHandler(h Handler, o *RedactOptions) Handler {
+	if o == nil {
+		o = &RedactOptions{}
+	}
+
+	r := &redactor{
+		rules:   make([]RedactRule, 0, len(o.Rules)),
+		hashKey: o.HashKey,
+		reveal:  o.Reveal,
+	}
+	if r.reveal <= 0 {
+		r.reveal = 4
+	}
+
+	for _, rule := range o.Rules {
+		if rule.Mode == RedactHash && len(r.hashKey) == 0 {
+			panic("RedactHash 需要指定 RedactOptions.HashKey")
+		}
+
+		rule.Key = strings.ToLower(rule.Key)
+		if _, err := path.Match(rule.Key, ""); err != nil {
+			panic(fmt.Sprintf("无效的 Key %s:%v", rule.Key, err))
+		}
+		r.rules = append(r.rules, rule)
+	}
+
+	return &redactHandler{h: h, r: r}
+}
+
+func (h *redactHandler) Handle(e *Record) {
+	attrs, changed := h.r.redactAttrs(e.Attrs)
+	if !changed {
+		h.h.Handle(e)
+		return
+	}
+
+	// e 可能是由其它 Handler 或 goroutine 共享的快照，不能修改，只能传递其副本。
+	r := *e
+	r.Attrs = attrs
+	h.h.Handle(&r)
+}
+
+func (h *redactHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	attrs, _ = h.r.redactAttrs(attrs)
+	return &redactHandler{h: h.h.New(detail, lv, attrs), r: h.r}
+}
+
+// 返回处理之后的属性
+//
+// 只有在有属性被修改时才会生成新的切片，此时 changed 为 true。
+func (r *redactor) redactAttrs(attrs []Attr) (ret []Attr, changed bool) {
+	for i, a := range attrs {
+		v, keep, ok := r.redactAttr(a)
+		if !ok {
+			if changed && keep {
+				ret = append(ret, a)
+			}
+			continue
+		}
+
+		if !changed {
+			ret = make([]Attr, 0, len(attrs))
+			ret = append(ret, attrs[:i]...)
+			changed = true
+		}
+		if keep {
+			ret = append(ret, Attr{K: a.K, V: v})
+		}
+	}
+
+	if !changed {
+		return attrs, false
+	}
+	return ret, true
+}
+
+// 处理单个属性
+//
+// keep 表示是否保留该属性；ok 表示该属性是否被修改。
+func (r *redactor) redactAttr(a Attr) (v any, keep, ok bool) {
+	if g, isGroup := a.V.([]Attr); isGroup {
+		attrs, changed := r.redactAttrs(g)
+		return attrs, true, changed
+	}
+
+	for _, rule := range r.rules {
+		if !rule.matchKey(a.K) {
+			continue
+		}
+
+		if rule.Value == nil {
+			if rule.Mode == RedactRemove {
+				return nil, false, true
+			}
+			return r.replace(rule.Mode, fmt.Sprint(a.V)), true, true
+		}
+
+		s, isString := stringValue(a.V)
+		if !isString || !rule.Value.MatchString(s) {
+			continue
+		}
+		if rule.Mode == RedactRemove {
+			return nil, false, true
+		}
+		return rule.Value.ReplaceAllStringFunc(s, func(s string) string { return r.replace(rule.Mode, s) }), true, true
+	}
+
+	return a.V, true, false
+}
+
+func (rule *RedactRule) matchKey(k string) bool {
+	if rule.Key == "" && rule.KeyRegexp == nil {
+		return true
+	}
+
+	if rule.Key != "" {
+		if ok, _ := path.Match(rule.Key, strings.ToLower(k)); ok {
+			return true
+		}
+	}
+	return rule.KeyRegexp != nil && rule.KeyRegexp.MatchString(k)
+}
+
+func (r *redactor) replace(mode RedactMode, s string) string {
+	switch mode {
+	case RedactPartial:
+		n := utf8.RuneCountInString(s)
+		if n <= r.reveal {
+			return partialMask
+		}
+		for range n - r.reveal {
+			_, size := utf8.DecodeRuneInString(s)
+			s = s[size:]
+		}
+		return partialMask + s
+	case RedactHash:
+		m := hmac.New(sha256.New, r.hashKey)
+		m.Write([]byte(s))
+		return hex.EncodeToString(m.Sum(nil))
+	default:
+		return redactedValue
+	}
+}
+
+func stringValue(v any) (string, bool) {
+	switch vv := v.(type) {
+	case string:
+		return vv, true
+	case []byte:
+		return string(vv), true
+	case error:
+		return vv.Error(), true
+	case fmt.Stringer:
+		return vv.String(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestNewRedactHandler(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.PanicString(func() {
+		NewRedactHandler(NewNopHandler(), &RedactOptions{Rules: []RedactRule{{Key: "k", Mode: RedactHash}}})
+	}, "RedactHash 需要指定 RedactOptions.HashKey")
+
+	a.Panic(func() {
+		NewRedactHandler(NewNopHandler(), &RedactOptions{Rules: []RedactRule{{Key: "[", Mode: RedactMask}}})
+	})
+
+	buf := new(bytes.Buffer)
+	New(NewRedactHandler(NewTextHandler(buf), nil)).INFO().With("password", "123").String("abc")
+	a.Equal(buf.String(), "[INFO] abc password=123\n")
+}
+
+func TestRedactHandler(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	h := NewRedactHandler(NewTextHandler(buf), &RedactOptions{
+		Rules: []RedactRule{
+			{Key: "password", Mode: RedactRemove},
+			{Key: "*_token", Mode: RedactMask},
+			{KeyRegexp: regexp.MustCompile(`^(?i)authorization$`), Mode: RedactMask},
+			{Key: "card", Mode: RedactPartial},
+			{Key: "uid", Mode: RedactHash},
+			{Value: RedactEmail, Mode: RedactMask},
+			{Value: RedactJWT, Mode: RedactRemove},
+		},
+		HashKey: []byte("key"),
+	})
+	l := New(h, WithAttrs(map[string]any{"Access_Token": "abc"}))
+
+	l.INFO().
+		With("password", "123").
+		With("Authorization", "Bearer xx").
+		With("card", 4111111111111111).
+		With("short", "x").
+		With("msg", "send to a@example.com and b@example.com").
+		With("jwt", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig").
+		With("g", []Attr{{K: "refresh_token", V: "t"}, {K: "k", V: 1}}).
+		String("abc")
+	a.Equal(buf.String(), "[INFO] abc Access_Token=*** Authorization=*** card=****1111 short=x msg=send to *** and *** g.refresh_token=*** g.k=1\n")
+
+	// RedactHash 的结果是相同的
+	buf.Reset()
+	l.INFO().With("uid", 1).String("1")
+	l.INFO().With("uid", "1").String("2")
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	a.Length(lines, 2)
+	uid1 := bytes.TrimPrefix(lines[0], []byte("[INFO] 1 Access_Token=*** "))
+	uid2 := bytes.TrimPrefix(lines[1], []byte("[INFO] 2 Access_Token=*** "))
+	a.Equal(uid1, uid2).Length(uid1, len("uid=")+64).NotContains(string(uid1), "uid=1")
+
+	// 不修改原始的属性
+	buf.Reset()
+	raw := new(bytes.Buffer)
+	l = New(MergeHandler(h, NewTextHandler(raw)))
+	l.INFO().With("password", "123").String("abc")
+	a.Equal(buf.String(), "[INFO] abc\n").
+		Equal(raw.String(), "[INFO] abc password=123\n")
+
+	// Partial 的保留长度
+	buf.Reset()
+	l = New(NewRedactHandler(NewTextHandler(buf), &RedactOptions{
+		Rules:  []RedactRule{{Key: "phone", Mode: RedactPartial}},
+		Reveal: 2,
+	}))
+	l.INFO().With("phone", "手机13800138000").String("abc")
+	a.Equal(buf.String(), "[INFO] abc phone=****00\n")
+}
+
+func TestRedactHandler_alloc(t *testing.T) {
+	a := assert.New(t, false)
+	h := NewRedactHandler(NewNopHandler(), &RedactOptions{Rules: []RedactRule{{Key: "password", Mode: RedactRemove}}})
+	l := New(h)
+	e := l.NewRecord()
+	e.Attrs = []Attr{{K: "k1", V: 1}, {K: "k2", V: "v2"}}
+	a.Equal(testing.AllocsPerRun(100, func() { h.Handle(e) }), 0)
+}
+
+func TestRedactHandler_frozen(t *testing.T) {
+	a := assert.New(t, false)
+	buf := &syncBuffer{}
+	h := NewRedactHandler(NewTextHandler(buf), &RedactOptions{Rules: []RedactRule{{Key: "password", Mode: RedactRemove}}})
+	l := New(h)
+	e := l.NewRecord()
+	e.Attrs = []Attr{{K: "password", V: "123"}}
+	f := e.DepthString(1, "msg").Freeze()
+
+	hh := l.INFO().Handler()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() { hh.Handle(f) })
+	}
+	wg.Wait()
+	a.Equal(f.Attrs, []Attr{{K: "password", V: "123"}}).
+		Equal(buf.String(), strings.Repeat("[INFO] msg\n", 4))
+}
@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// RingHandler 在内存中保存最近日志的 [Handler]
+	//
+	// 保存的是由 [Record.Freeze] 生成的副本，可以通过 [RingHandler.Records] 查询，
+	// 同时也实现了 [http.Handler] 接口，以 JSON 的形式输出查询结果。
+	RingHandler struct {
+		r     *ring
+		lv    Level
+		attrs []Attr
+	}
+
+	// RingQuery [RingHandler.Records] 的查询条件
+	//
+	// 零值表示不作限制。
+	RingQuery struct {
+		// Levels 日志级别
+		Levels []Level
+
+		// Start 和 End 表示日志的创建时间范围，包含 Start 但不包含 End。
+		Start, End time.Time
+
+		// Message 日志消息中需要包含的内容
+		Message string
+
+		// Attrs 日志需要包含的属性
+		//
+		// 值以 [fmt.Sprint] 转换之后进行比较。
+		Attrs map[string]string
+
+		// Limit 最多返回的数量，超出时仅返回最新的日志。
+		Limit int
+	}
+
+	ring struct {
+		mux      sync.Mutex
+		items    []ringItem
+		head     int // 最早的记录的位置
+		count    int
+		size     int
+		maxBytes int
+	}
+
+	ringItem struct {
+		r    *Record
+		size int
+	}
+
+	ringRecord struct {
+		Level   string         `json:"level"`
+		Time    time.Time      `json:"time"`
+		Caller  string         `json:"caller,omitempty"`
+		Message string         `json:"message"`
+		Attrs   map[string]any `json:"attrs,omitempty"`
+	}
+)
+
+// NewRingHandler 声明 [RingHandler]
+//
+// count 表示最多保存的日志数量，必须大于 0；
+// maxBytes 表示最多保存的日志大小，以日志的消息和属性的长度计算，0 表示不限制。
+// 超出限制时，会删除最早的日志。
+func NewRingHandler(count, maxBytes int) *RingHandler {
+	if count <= 0 {
+		panic("参数 count 必须大于 0")
+	}
+
+	return &RingHandler{r: &ring{items: make([]ringItem, count), maxBytes: maxBytes}}
+}
+
+func (h *RingHandler) Handle(e *Record) {
+	r := freezeRecord(e, h.lv, h.attrs)
+
+	b := NewBuffer(false)
+	appendRecordText(b, r)
+	size := b.Len()
+	b.Free()
+
+	h.r.push(r, size)
+}
+
+func (h *RingHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	as := make([]Attr, 0, len(h.attrs)+len(attrs))
+	as = append(as, h.attrs...)
+	as = append(as, cloneAttrs(attrs)...)
+	return &RingHandler{r: h.r, lv: lv, attrs: as}
+}
+
+// 生成 e 的快照，同时将 [Handler.New] 中的参数一并保存在快照中。
+//
+// e 可能是由其它 [Handler] 共享的快照，所以必须生成新的对象之后再修改。
+func freezeRecord(e *Record, lv Level, attrs []Attr) *Record {
+	r := e.snapshot()
+	r.lv = lv
+	if r.created.IsZero() { // 未指定 WithCreated 也需要时间用于查询
+		r.created = time.Now()
+	}
+	if len(attrs) > 0 {
+		r.Attrs = append(slices.Clip(attrs), r.Attrs...)
+	}
+	return r
+}
+
+func (r *ring) push(rr *Record, size int) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.count == len(r.items) {
+		r.pop()
+	}
+	r.items[(r.head+r.count)%len(r.items)] = ringItem{r: rr, size: size}
+	r.count++
+	r.size += size
+
+	for r.maxBytes > 0 && r.size > r.maxBytes && r.count > 1 {
+		r.pop()
+	}
+}
+
+func (r *ring) pop() {
+	r.size -= r.items[r.head].size
+	r.items[r.head] = ringItem{}
+	r.head = (r.head + 1) % len(r.items)
+	r.count--
+}
+
+// 按时间顺序返回所有的记录
+func (r *ring) records() []*Record {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	rs := make([]*Record, 0, r.count)
+	for i := range r.count {
+		rs = append(rs, r.items[(r.head+i)%len(r.items)].r)
+	}
+	return rs
+}
+
+// Records 按时间顺序返回符合 q 的日志
+//
+// q 可以为 nil，表示返回所有的日志。
+// 返回的对象由 [Record.Freeze] 生成，调用者不应该修改其内容。
+func (h *RingHandler) Records(q *RingQuery) []*Record {
+	rs := h.r.records()
+	if q == nil {
+		return rs
+	}
+
+	rs = slices.DeleteFunc(rs, func(r *Record) bool { return !q.match(r) })
+	if q.Limit > 0 && len(rs) > q.Limit {
+		rs = rs[len(rs)-q.Limit:]
+	}
+	return rs
+}
+
+func (q *RingQuery) match(r *Record) bool {
+	if len(q.Levels) > 0 && slices.Index(q.Levels, r.Level()) < 0 {
+		return false
+	}
+
+	if !q.Start.IsZero() && r.Time().Before(q.Start) {
+		return false
+	}
+	if !q.End.IsZero() && !r.Time().Before(q.End) {
+		return false
+	}
+
+	if q.Message != "" && !strings.Contains(r.Message(), q.Message) {
+		return false
+	}
+
+	for k, v := range q.Attrs {
+		index := slices.IndexFunc(r.Attrs, func(a Attr) bool { return a.K == k && fmt.Sprint(a.V) == v })
+		if index < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ServeHTTP 以 JSON 的形式输出日志
+//
+// 支持以下查询参数，分别对应 [RingQuery] 的各个字段：
+//   - level 日志级别，可以指定多个；
+//   - start 和 end 时间范围，采用 [time.RFC3339] 格式；
+//   - msg 日志消息中需要包含的内容；
+//   - attr 以 key:value 的形式表示的属性，可以指定多个；
+//   - limit 最多返回的数量；
+func (h *RingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseRingQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rs := h.Records(q)
+	list := make([]*ringRecord, 0, len(rs))
+	for _, rr := range rs {
+		list = append(list, newRingRecord(rr))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func newRingRecord(r *Record) *ringRecord {
+	rr := &ringRecord{
+		Level:   r.Level().String(),
+		Time:    r.Time(),
+		Message: r.Message(),
+		Attrs:   attrs2Map(r.Attrs),
+	}
+	if r.AppendLocation != nil {
+		b := NewBuffer(false)
+		b.AppendFunc(r.AppendLocation)
+		rr.Caller = string(b.Bytes())
+		b.Free()
+	}
+	return rr
+}
+
+func parseRingQuery(r *http.Request) (*RingQuery, error) {
+	vals := r.URL.Query()
+	q := &RingQuery{Message: vals.Get("msg")}
+
+	for _, v := range vals["level"] {
+		lv, err := ParseLevel(v)
+		if err != nil {
+			return nil, err
+		}
+		q.Levels = append(q.Levels, lv)
+	}
+
+	var err error
+	if v := vals.Get("start"); v != "" {
+		if q.Start, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, err
+		}
+	}
+	if v := vals.Get("end"); v != "" {
+		if q.End, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := vals.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range vals["attr"] {
+		k, val, found := strings.Cut(v, ":")
+		if !found {
+			return nil, fmt.Errorf("无效的 attr 参数 %s", v)
+		}
+		if q.Attrs == nil {
+			q.Attrs = make(map[string]string, 5)
+		}
+		q.Attrs[k] = val
+	}
+
+	return q, nil
+}
+
+func attrs2Map(attrs []Attr) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		switch v := a.V.(type) {
+		case []Attr:
+			m[a.K] = attrs2Map(v)
+		case error:
+			m[a.K] = v.Error()
+		default:
+			if _, err := json.Marshal(v); err != nil { // 防止单个属性造成整个输出失败
+				m[a.K] = fmt.Sprint(v)
+			} else {
+				m[a.K] = v
+			}
+		}
+	}
+	return m
+}
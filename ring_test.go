@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestRingHandler(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Panic(func() { NewRingHandler(0, 0) })
+
+	h := NewRingHandler(3, 0)
+	l := New(h, WithAttrs(map[string]any{"app": "test"}))
+	start := time.Now()
+	l.INFO().String("1")
+	l.DEBUG().With("uid", 5).String("2")
+	l.ERROR().String("3 failed")
+	l.WARN().With("uid", 6).String("4")
+
+	rs := h.Records(nil)
+	a.Length(rs, 3).
+		Equal(rs[0].Message(), "2").
+		Equal(rs[0].Level(), LevelDebug).
+		Equal(rs[0].Attrs, []Attr{{K: "app", V: "test"}, {K: "uid", V: 5}}).
+		Equal(rs[2].Message(), "4")
+
+	a.Length(h.Records(&RingQuery{Levels: []Level{LevelError, LevelWarn}}), 2).
+		Length(h.Records(&RingQuery{Message: "fail"}), 1).
+		Length(h.Records(&RingQuery{Attrs: map[string]string{"uid": "5"}}), 1).
+		Length(h.Records(&RingQuery{Attrs: map[string]string{"uid": "5", "app": "test"}}), 1).
+		Length(h.Records(&RingQuery{Attrs: map[string]string{"uid": "7"}}), 0).
+		Length(h.Records(&RingQuery{Start: start}), 3).
+		Length(h.Records(&RingQuery{End: start}), 0).
+		Length(h.Records(&RingQuery{Limit: 1}), 1)
+
+	// maxBytes
+	h = NewRingHandler(10, 5)
+	l = New(h)
+	l.INFO().String("123")
+	l.INFO().String("456")
+	l.INFO().String("7890123")
+	rs = h.Records(nil)
+	a.Length(rs, 1).Equal(rs[0].Message(), "7890123")
+
+	// 不会修改由其它 Handler 共享的快照
+	buf := new(bytes.Buffer)
+	h = NewRingHandler(10, 0)
+	l = New(MergeHandler(h, NewTextHandler(buf)))
+	tl := l.NewTail(map[string]any{"req": 1}, nil)
+	tl.DEBUG().String("dbg")
+	tl.ERROR().String("err")
+	FreeAttrLogs(tl)
+	a.Equal(buf.String(), "[DBUG] dbg req=1\n[ERRO] err req=1\n")
+	rs = h.Records(nil)
+	a.Length(rs, 2).
+		Equal(rs[0].Attrs, []Attr{{K: "req", V: 1}}).
+		Equal(rs[0].Level(), LevelDebug)
+}
+
+func TestRingHandler_ServeHTTP(t *testing.T) {
+	a := assert.New(t, false)
+
+	h := NewRingHandler(10, 0)
+	l := New(h, WithLocation(true))
+	l.INFO().With("uid", 5).With("g", []Attr{{K: "k", V: "v"}}).String("info")
+	l.ERROR().With("ch", make(chan int)).String("error")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?level=info&attr=uid:5", nil))
+	a.Equal(w.Code, http.StatusOK).
+		Equal(w.Header().Get("Content-Type"), "application/json; charset=utf-8")
+	var list []*ringRecord
+	a.NotError(json.Unmarshal(w.Body.Bytes(), &list)).Length(list, 1)
+	a.Equal(list[0].Message, "info").
+		Equal(list[0].Level, "INFO").
+		Contains(list[0].Caller, "ring_test.go:").
+		Equal(list[0].Attrs, map[string]any{"uid": 5.0, "g": map[string]any{"k": "v"}})
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?msg=err", nil))
+	list = nil
+	a.Equal(w.Code, http.StatusOK).
+		NotError(json.Unmarshal(w.Body.Bytes(), &list)).
+		Length(list, 1)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?level=xx", nil))
+	a.Equal(w.Code, http.StatusBadRequest)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?attr=xx", nil))
+	a.Equal(w.Code, http.StatusBadRequest)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?start=xx", nil))
+	a.Equal(w.Code, http.StatusBadRequest)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	a.Equal(w.Code, http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type (
+	// SamplingPolicy [NewSamplingHandler] 的采样策略
+	//
+	// [LevelError] 和 [LevelFatal] 的日志始终会被输出，不受采样策略的影响。
+	SamplingPolicy struct {
+		// Tick 统计周期
+		//
+		// 默认值为 1 秒。
+		Tick time.Duration
+
+		// First 每个周期内相同级别和消息模板的日志，最先输出的数量
+		//
+		// 消息模板指 [Recorder.Printf] 的 format 参数或是 [Recorder.String] 的参数，
+		// 其它方法输出的日志则以最终的消息内容作为模板。
+		First int
+
+		// Thereafter 超过 First 之后，每 Thereafter 条日志输出一条
+		//
+		// 0 表示丢弃超过 First 之后的所有日志。
+		// 如果 First 和 Thereafter 都为 0，表示不采用此规则。
+		Thereafter int
+
+		// Rates 按概率采样的日志级别及其概率
+		//
+		// 概率的取值范围为 [0, 1]，在此指定的级别不再采用 First 和 Thereafter 的规则。
+		Rates map[Level]float64
+	}
+
+	samplingHandler struct {
+		h  Handler
+		lv Level
+		s  *sampler
+	}
+
+	sampler struct {
+		mux sync.Mutex
+
+		tick       time.Duration
+		first      int
+		thereafter int
+		rates      map[Level]float64
+
+		end     time.Time // 当前周期的结束时间
+		counts  map[samplingKey]int
+		dropped [LevelFatal + 1]int
+		timer   *time.Timer // 输出汇总信息的定时器，没有被丢弃的日志时为空。
+		logs    *Logs
+
+		summary Handler
+	}
+
+	samplingKey struct {
+		lv       Level
+		template string
+	}
+)
+
+// NewSamplingHandler 返回按 p 对日志进行采样的 [Handler]
+//
+// 如果周期内有被丢弃的日志，会在该周期结束时由定时器以一条 [LevelWarn] 级别的汇总日志输出到 h，
+// 汇总日志的属性名为各日志级别的名称，值为该级别被丢弃的数量。
+//
+// p 可以为 nil，表示不作任何采样。
+func NewSamplingHandler(h Handler, p *SamplingPolicy) Handler {
+	if p == nil {
+		p = &SamplingPolicy{}
+	}
+
+	s := &sampler{
+		tick:       p.Tick,
+		first:      p.First,
+		thereafter: p.Thereafter,
+		rates:      p.Rates,
+
+		counts:  make(map[samplingKey]int, 100),
+		summary: h.New(false, LevelWarn, nil),
+	}
+	if s.tick <= 0 {
+		s.tick = time.Second
+	}
+
+	return &samplingHandler{h: h, s: s}
+}
+
+func (h *samplingHandler) Handle(e *Record) {
+	if h.lv == LevelError || h.lv == LevelFatal || h.s.sample(h.lv, e) {
+		h.h.Handle(e)
+	}
+}
+
+func (h *samplingHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	return &samplingHandler{h: h.h.New(detail, lv, attrs), lv: lv, s: h.s}
+}
+
+// 判断 e 是否需要输出
+func (s *sampler) sample(lv Level, e *Record) (keep bool) {
+	var template string
+	_, hasRate := s.rates[lv]
+	if !hasRate && (s.first > 0 || s.thereafter > 0) {
+		if template = e.template; template == "" {
+			template = e.Message()
+		}
+	}
+
+	now := time.Now()
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if now.After(s.end) {
+		clear(s.counts)
+		s.end = now.Add(s.tick)
+	}
+
+	switch {
+	case hasRate:
+		keep = rand.Float64() < s.rates[lv]
+	case s.first > 0 || s.thereafter > 0:
+		key := samplingKey{lv: lv, template: template}
+		n := s.counts[key] + 1
+		s.counts[key] = n
+		keep = n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0)
+	default:
+		keep = true
+	}
+
+	if !keep {
+		s.dropped[lv]++
+		if s.timer == nil { // 在当前周期结束时输出汇总信息
+			s.logs = e.logs
+			s.timer = time.AfterFunc(s.end.Sub(now), s.flush)
+		}
+	}
+	return keep
+}
+
+// 输出汇总信息
+func (s *sampler) flush() {
+	s.mux.Lock()
+	dropped, l := s.dropped, s.logs
+	s.dropped = [LevelFatal + 1]int{}
+	s.timer = nil
+	s.mux.Unlock()
+
+	if dropped == [LevelFatal + 1]int{} {
+		return
+	}
+
+	r := l.NewRecord()
+	r.lv = LevelWarn
+	t := l.now()
+	r.created = t
+	if l.createdFormat != "" {
+		r.AppendCreated = func(b *Buffer) { b.AppendTime(t, l.createdFormat) }
+	}
+	r.AppendMessage = func(b *Buffer) { b.AppendString("sampling dropped") }
+
+	for lv, n := range dropped {
+		if n > 0 {
+			r.With(Level(lv).String(), n)
+		}
+	}
+
+	s.summary.Handle(r)
+	recordPool.Put(r)
+}
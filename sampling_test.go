@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	h := NewSamplingHandler(NewTextHandler(buf), &SamplingPolicy{Tick: time.Hour, First: 2, Thereafter: 3})
+	l := New(h)
+
+	for i := range 10 {
+		l.DEBUG().Printf("debug %d", i)
+		l.INFO().String("info")
+		l.ERROR().String("error")
+	}
+	l.INFO().String("other")
+	out := buf.String()
+	// 输出第 1、2、5、8 条
+	a.Equal(strings.Count(out, "[DBUG] debug"), 4).
+		Contains(out, "[DBUG] debug 0\n").
+		Contains(out, "[DBUG] debug 1\n").
+		Contains(out, "[DBUG] debug 4\n").
+		Contains(out, "[DBUG] debug 7\n").
+		Equal(strings.Count(out, "[INFO] info\n"), 4).
+		Equal(strings.Count(out, "[ERRO] error\n"), 10).
+		Equal(strings.Count(out, "[INFO] other\n"), 1).
+		NotContains(out, "sampling dropped")
+
+	// 周期结束时输出汇总信息
+	buf.Reset()
+	h.(*samplingHandler).s.flush()
+	a.Equal(buf.String(), "[WARN] sampling dropped INFO=6 DBUG=6\n")
+
+	// 没有被丢弃的日志，不再输出汇总信息。
+	buf.Reset()
+	h.(*samplingHandler).s.flush()
+	a.Empty(buf.String())
+}
+
+func TestSamplingHandler_timer(t *testing.T) {
+	a := assert.New(t, false)
+	buf := &syncBuffer{}
+
+	l := New(NewSamplingHandler(NewTextHandler(buf), &SamplingPolicy{Tick: 50 * time.Millisecond, First: 1}))
+	for range 3 {
+		l.INFO().String("info")
+	}
+	a.Equal(buf.String(), "[INFO] info\n")
+
+	// 之后没有任何日志，汇总信息依然会在周期结束时输出。
+	time.Sleep(200 * time.Millisecond)
+	a.Equal(buf.String(), "[INFO] info\n[WARN] sampling dropped INFO=2\n")
+}
+
+func TestSamplingHandler_rates(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	h := NewSamplingHandler(NewTextHandler(buf), &SamplingPolicy{
+		First: 1,
+		Rates: map[Level]float64{LevelTrace: 0, LevelDebug: 1, LevelFatal: 0},
+	})
+	l := New(h)
+	for range 5 {
+		l.TRACE().String("trace")
+		l.DEBUG().String("debug")
+		l.INFO().String("info")
+		l.FATAL().String("fatal")
+		l.WARN().With("k", "v").Print("warn")
+	}
+	out := buf.String()
+	a.Equal(strings.Count(out, "[TRAC] trace\n"), 0).
+		Equal(strings.Count(out, "[DBUG] debug\n"), 5).
+		Equal(strings.Count(out, "[INFO] info\n"), 1).
+		Equal(strings.Count(out, "[FATL] fatal\n"), 5). // 始终输出
+		Equal(strings.Count(out, "[WARN] warn k=v\n"), 1)
+
+	// 不采样
+	buf.Reset()
+	l = New(NewSamplingHandler(NewTextHandler(buf), &SamplingPolicy{}))
+	for range 5 {
+		l.INFO().String("info")
+	}
+	a.Equal(strings.Count(buf.String(), "[INFO] info\n"), 5)
+}
+
+func TestSamplingHandler_nil(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	l := New(NewSamplingHandler(NewTextHandler(buf), nil))
+	for range 3 {
+		l.INFO().String("msg")
+	}
+	a.Equal(buf.String(), strings.Repeat("[INFO] msg\n", 3))
+}
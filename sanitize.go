@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"unicode/utf8"
+
+	"github.com/issue9/localeutil"
+	"golang.org/x/xerrors"
+)
+
+// DisableSanitize 禁止 h 对输出内容中的控制字符进行转义
+//
+// 由 [NewTextHandler]、[NewTermHandler] 和 [NewPatternHandler] 返回的对象，
+// 默认会将消息和属性中的 CR、LF、ESC 等 C0 和 C1 控制字符转义成 \n、\x1b 等形式，
+// 以防止通过换行符伪造日志，或是通过 ANSI 控制序列攻击终端。
+// 调用此函数之后 h 及其通过 [Handler.New] 派生的对象将原样输出内容。
+//
+// 对于由 [MergeHandler] 和 [NewDispatchHandler] 返回的对象，会作用于其包含的所有对象；
+// 其它类型的对象则不作任何处理。
+//
+// 返回值即为 h 本身。
+func DisableSanitize(h Handler) Handler {
+	switch hh := h.(type) {
+	case *textHandler:
+		hh.raw = true
+	case *termHandler:
+		hh.raw = true
+	case *patternHandler:
+		hh.raw = true
+	case *mergeHandler:
+		for _, item := range hh.handlers {
+			DisableSanitize(item)
+		}
+	case *dispatchHandler:
+		for _, item := range hh.handlers {
+			DisableSanitize(item)
+		}
+	}
+	return h
+}
+
+// 向 b 输出 e 的消息并转义其中的控制字符
+//
+// 实现了 [xerrors.Formatter] 的错误，只有调用堆栈等详细信息会原样输出，
+// 错误信息本身依然会被转义。
+func appendSanitizedMessage(b *Buffer, e *Record) {
+	if ef, ok := e.Err().(xerrors.Formatter); ok {
+		var p *localeutil.Printer
+		if e.logs != nil {
+			p = e.logs.printer
+		}
+		appendError(p, b, ef, true)
+		return
+	}
+
+	start := b.Len()
+	b.AppendFunc(e.AppendMessage)
+	sanitize(b, start)
+}
+
+// 会转义控制字符的 [xerrors.Printer]
+//
+// 在调用 Detail 之后输出的内容被视为调用堆栈等详细信息，不作转义，
+// 直到开始输出错误链中的下一个错误。
+type sanitizePrinter struct {
+	b      *Buffer
+	detail bool
+}
+
+func (p *sanitizePrinter) Print(v ...any) {
+	start := p.b.Len()
+	p.b.Print(v...)
+	p.sanitize(start)
+}
+
+func (p *sanitizePrinter) Printf(format string, v ...any) {
+	start := p.b.Len()
+	p.b.Printf(format, v...)
+	p.sanitize(start)
+}
+
+func (p *sanitizePrinter) sanitize(start int) {
+	if p.detail {
+		return
+	}
+
+	// 末尾的换行符通常用于分隔错误链中的各个错误，之后并没有其它内容，予以保留。
+	if end := p.b.Len() - 1; end >= start && p.b.data[end] == '\n' {
+		p.b.data = p.b.data[:end]
+		sanitize(p.b, start)
+		p.b.data = append(p.b.data, '\n')
+		return
+	}
+	sanitize(p.b, start)
+}
+
+func (p *sanitizePrinter) Detail() bool {
+	p.detail = true
+	return p.b.Detail()
+}
+
+// 转义 b 中从 start 开始的内容中的控制字符
+//
+// 如果不包含控制字符，则不会产生任何内存分配。
+func sanitize(b *Buffer, start int) {
+	index := controlIndex(b.data[start:])
+	if index < 0 {
+		return
+	}
+	index += start
+
+	tmp := NewBuffer(false)
+	defer tmp.Free()
+	tmp.AppendBytes(b.data[index:]...)
+
+	b.data = b.data[:index]
+	data := tmp.data
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '\n':
+				b.AppendString(`\n`)
+			case c == '\r':
+				b.AppendString(`\r`)
+			case c == '\t':
+				b.AppendString(`\t`)
+			case c < ' ' || c == 0x7f:
+				b.AppendString(`\x`).AppendBytes(hexDigits[c>>4], hexDigits[c&0xf])
+			default:
+				b.AppendBytes(c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(data[i:])
+		switch {
+		case r == utf8.RuneError && size == 1 && c <= 0x9f: // 单字节的 C1 控制字符
+			b.AppendString(`\x`).AppendBytes(hexDigits[c>>4], hexDigits[c&0xf])
+		case r >= 0x80 && r <= 0x9f:
+			b.AppendString(`\u00`).AppendBytes(hexDigits[r>>4], hexDigits[r&0xf])
+		default:
+			b.AppendBytes(data[i : i+size]...)
+		}
+		i += size
+	}
+}
+
+// 返回第一个需要转义的字节的位置，不存在则返回 -1。
+func controlIndex(data []byte) int {
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c < utf8.RuneSelf {
+			if c < ' ' || c == 0x7f {
+				return i
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(data[i:])
+		if (r == utf8.RuneError && size == 1 && c <= 0x9f) || (r >= 0x80 && r <= 0x9f) {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
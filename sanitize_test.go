@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+	"golang.org/x/xerrors"
+)
+
+func TestSanitize(t *testing.T) {
+	a := assert.New(t, false)
+
+	data := []struct {
+		input, output string
+	}{
+		{input: "", output: ""},
+		{input: "abc 中文", output: "abc 中文"},
+		{input: "a\r\n[ERRO] fake", output: `a\r\n[ERRO] fake`},
+		{input: "\x1b[31mred\x1b[0m", output: `\x1b[31mred\x1b[0m`},
+		{input: "a\tb\x00\x7f", output: `a\tb\x00\x7f`},
+		{input: "c1\u009b31m", output: `c1\u009b31m`},
+		{input: "raw\x9b31m", output: `raw\x9b31m`},
+		{input: "invalid\xff", output: "invalid\xff"},
+	}
+
+	for _, item := range data {
+		b := NewBuffer(false)
+		b.AppendString("prefix\n").AppendString(item.input)
+		sanitize(b, 7) // 只处理 start 之后的内容
+		a.Equal(string(b.Bytes()), "prefix\n"+item.output, item.input)
+		b.Free()
+	}
+
+	b := NewBuffer(false)
+	defer b.Free()
+	b.AppendString("clean input")
+	a.Equal(testing.AllocsPerRun(100, func() { sanitize(b, 0) }), 0)
+}
+
+func TestDisableSanitize(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	l := New(NewTextHandler(buf))
+	l.INFO().With("k\n", "v\x1b").String("line1\nline2")
+	a.Equal(buf.String(), `[INFO] line1\nline2 k\n=v\x1b`+"\n")
+
+	buf.Reset()
+	l = New(DisableSanitize(NewTextHandler(buf)))
+	l.INFO().With("k", "v\x1b").String("line1\nline2")
+	a.Equal(buf.String(), "[INFO] line1\nline2 k=v\x1b\n")
+
+	buf.Reset()
+	l = New(NewPatternHandler("%msg|%attr{k}|%attrs", buf), WithAttrs(map[string]any{"a": "\r"}))
+	l.INFO().With("k", "v\n").String("\x1b")
+	a.Equal(buf.String(), `\x1b|v\n|a=\r k=v\n`+"\n")
+
+	buf.Reset()
+	l = New(DisableSanitize(NewPatternHandler("%msg|%attr{k}|%attrs", buf)), WithAttrs(map[string]any{"a": "\r"}))
+	l.INFO().With("k", "v\n").String("\x1b")
+	a.Equal(buf.String(), "\x1b|v\n|a=\r k=v\n\n")
+
+	buf.Reset()
+	l = New(DisableSanitize(MergeHandler(NewTermHandler(buf, nil), NewNopHandler())))
+	l.INFO().String("a\nb")
+	a.Contains(buf.String(), "a\nb\n")
+}
+
+func TestSanitize_xerrors(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+
+	// 错误信息需要转义
+	l := New(NewTextHandler(buf))
+	l.ERROR().Error(xerrors.Errorf("user input: %s", "x\n[ERRO] forged line"))
+	a.Equal(buf.String(), `[ERRO] user input: x\n[ERRO] forged line`+"\n")
+
+	// 调用堆栈原样输出
+	buf.Reset()
+	l = New(NewTextHandler(buf), WithDetail(true))
+	l.ERROR().Error(xerrors.Errorf("user input: %s", "x\x1b"))
+	a.True(strings.HasPrefix(buf.String(), `[ERRO] user input: x\x1bgithub.com/`), buf.String()).
+		Contains(buf.String(), "sanitize_test.go:").
+		Equal(strings.Count(buf.String(), "\n"), 3)
+
+	buf.Reset()
+	l = New(NewPatternHandler("%msg", buf))
+	l.ERROR().Error(xerrors.Errorf("a\rb: %w", errors.New("c\nd")))
+	a.Equal(buf.String(), `a\rbc\nd`+"\n")
+}
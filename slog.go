@@ -7,70 +7,204 @@ package logs
 import (
 	"context"
 	"log/slog"
-	"runtime"
 	"slices"
 )
 
-var slog2Logs = map[slog.Level]Level{
-	slog.LevelInfo:  LevelInfo,
-	slog.LevelDebug: LevelDebug,
-	slog.LevelWarn:  LevelWarn,
-	slog.LevelError: LevelError,
-}
-
 type slogHandler struct {
 	l      *Logs
-	attrs  []slog.Attr
-	prefix string // groups 组成
+	attrs  []Attr // 不属于任何分组的属性
+	groups []slogGroup
+}
+
+// 由 [slog.Handler.WithGroup] 开启的分组
+type slogGroup struct {
+	name  string
+	attrs []Attr
 }
 
 // SLogHandler 将 logs 转换为 [slog.Handler] 接口
 //
-// 所有的 group 会作为普通 attr 的名称前缀，但是不影响 Level、Message 等字段。
+// group 会以嵌套的 [Attr] 形式保存，即 [Attr.V] 的类型为 []Attr，
+// 具体的输出方式由 [Handler] 决定，比如 [NewJSONHandler] 会输出嵌套的对象，
+// 而 [NewTextHandler] 则以 group.key=value 的形式输出。
+// 实现了 [slog.LogValuer] 的值会被解析之后再保存。
+//
+// [slog.Level] 会按区间映射到 [Level]：
+// 小于 [slog.LevelDebug] 的为 [LevelTrace]，大于等于 [slog.LevelError]+4 的为 [LevelFatal]，
+// 其它的则映射到不大于其值的最接近的级别。
 func (logs *Logs) SLogHandler() slog.Handler { return &slogHandler{l: logs} }
 
+// WithReplaceAttr 指定 [Logs.SLogHandler] 处理属性的函数
+//
+// 功能与 [slog.HandlerOptions.ReplaceAttr] 相同，但仅作用于普通的属性，
+// 不包含时间、级别、消息等内置字段。
+func WithReplaceAttr(f func(groups []string, a slog.Attr) slog.Attr) Option {
+	return func(l *Logs) { l.replaceAttr = f }
+}
+
+func slogLevel(lv slog.Level) Level {
+	switch {
+	case lv >= slog.LevelError+4:
+		return LevelFatal
+	case lv >= slog.LevelError:
+		return LevelError
+	case lv >= slog.LevelWarn:
+		return LevelWarn
+	case lv >= slog.LevelInfo:
+		return LevelInfo
+	case lv >= slog.LevelDebug:
+		return LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
 func (h *slogHandler) Enabled(ctx context.Context, lv slog.Level) bool {
-	return h.l.IsEnable(slog2Logs[lv])
+	return h.l.IsEnable(slogLevel(lv))
 }
 
 func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	lv := slogLevel(r.Level)
 	rr := h.l.NewRecord()
-	rr.AppendCreated = func(b *Buffer) { b.AppendTime(r.Time, h.l.createdFormat) }
+	rr.lv = lv
+	rr.leveled = true
+	rr.created = r.Time
+	if rr.created.IsZero() || h.l.clock != nil { // slog.Record.Time 为零值表示忽略时间
+		rr.created = h.l.now()
+	}
+	if h.l.createdFormat != "" {
+		t := rr.created
+		rr.AppendCreated = func(b *Buffer) { b.AppendTime(t, h.l.createdFormat) }
+	}
+	rr.template = r.Message
 	rr.AppendMessage = func(b *Buffer) { b.AppendString(r.Message) }
 
-	for _, attr := range h.attrs {
-		rr.with(h.prefix+attr.Key, attr.Value)
+	rr.Attrs = append(rr.Attrs, h.attrs...)
+
+	// 记录中的属性属于最内层的分组
+	var attrs []Attr
+	if r.NumAttrs() > 0 {
+		names := h.groupNames()
+		attrs = make([]Attr, 0, r.NumAttrs())
+		r.Attrs(func(attr slog.Attr) bool {
+			attrs = h.appendAttr(attrs, names, attr)
+			return true
+		})
+	}
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		as := append(slices.Clip(g.attrs), attrs...)
+		if len(as) == 0 { // 空的分组会被忽略
+			attrs = nil
+			continue
+		}
+		attrs = []Attr{{K: g.name, V: as}}
 	}
-	r.Attrs(func(attr slog.Attr) bool {
-		rr.with(h.prefix+attr.Key, attr.Value)
-		return true
-	})
+	rr.Attrs = append(rr.Attrs, attrs...)
+
+	rr.withContext(ctx)
 
 	if r.PC != 0 {
-		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
-		rr.AppendLocation = func(b *Buffer) {
-			b.AppendString(f.File).AppendBytes(':').AppendInt(int64(f.Line), 10)
-		}
+		rr.setLocation(r.PC)
 	}
+	rr.captureStackFrom(r.PC)
 
-	rr.Output(h.l.Logger(slog2Logs[r.Level]))
+	rr.Output(h.l.Logger(lv))
 
 	return nil
 }
 
+func (h *slogHandler) groupNames() []string {
+	if len(h.groups) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(h.groups))
+	for _, g := range h.groups {
+		names = append(names, g.name)
+	}
+	return names
+}
+
+// 将 attr 转换成 [Attr] 并添加到 dst
+func (h *slogHandler) appendAttr(dst []Attr, groups []string, attr slog.Attr) []Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() != slog.KindGroup && h.l.replaceAttr != nil {
+		attr = h.l.replaceAttr(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+
+	if attr.Equal(slog.Attr{}) { // 与 slog 的约定相同，忽略空的属性。
+		return dst
+	}
+
+	v := attr.Value
+	switch v.Kind() {
+	case slog.KindGroup:
+		as := v.Group()
+		if len(as) == 0 {
+			return dst
+		}
+
+		if attr.Key == "" { // 没有名称的分组，直接展开。
+			for _, a := range as {
+				dst = h.appendAttr(dst, groups, a)
+			}
+			return dst
+		}
+
+		sub := make([]Attr, 0, len(as))
+		groups = append(slices.Clip(groups), attr.Key)
+		for _, a := range as {
+			sub = h.appendAttr(sub, groups, a)
+		}
+		if len(sub) == 0 {
+			return dst
+		}
+		return append(dst, Attr{K: attr.Key, V: sub})
+	case slog.KindString:
+		return append(dst, Attr{K: attr.Key, V: v.String()})
+	case slog.KindInt64:
+		return append(dst, Attr{K: attr.Key, V: v.Int64()})
+	case slog.KindUint64:
+		return append(dst, Attr{K: attr.Key, V: v.Uint64()})
+	case slog.KindFloat64:
+		return append(dst, Attr{K: attr.Key, V: v.Float64()})
+	case slog.KindBool:
+		return append(dst, Attr{K: attr.Key, V: v.Bool()})
+	case slog.KindDuration:
+		return append(dst, Attr{K: attr.Key, V: v.Duration()})
+	case slog.KindTime:
+		return append(dst, Attr{K: attr.Key, V: v.Time()})
+	default:
+		return append(dst, Attr{K: attr.Key, V: v.Any()})
+	}
+}
+
 func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	var a []slog.Attr
-	if len(h.attrs) == 0 {
-		a = attrs
-	} else {
-		a = append(slices.Clip(h.attrs), attrs...)
+	if len(attrs) == 0 {
+		return h
 	}
 
-	return &slogHandler{
-		l:      h.l,
-		attrs:  a,
-		prefix: h.prefix,
+	hh := &slogHandler{l: h.l, attrs: h.attrs, groups: h.groups}
+	if len(h.groups) == 0 {
+		as := slices.Clip(h.attrs)
+		for _, attr := range attrs {
+			as = h.appendAttr(as, nil, attr)
+		}
+		hh.attrs = as
+	} else { // 属性属于最内层的分组
+		hh.groups = slices.Clone(h.groups)
+		g := &hh.groups[len(hh.groups)-1]
+		names := h.groupNames()
+		as := slices.Clip(g.attrs)
+		for _, attr := range attrs {
+			as = h.appendAttr(as, names, attr)
+		}
+		g.attrs = as
 	}
+
+	return hh
 }
 
 func (h *slogHandler) WithGroup(name string) slog.Handler {
@@ -81,6 +215,6 @@ func (h *slogHandler) WithGroup(name string) slog.Handler {
 	return &slogHandler{
 		l:      h.l,
 		attrs:  h.attrs,
-		prefix: name + "." + h.prefix,
+		groups: append(slices.Clip(h.groups), slogGroup{name: name}),
 	}
 }
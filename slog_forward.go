@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+type slogForwardHandler struct {
+	h      slog.Handler
+	lv     slog.Level
+	detail bool
+}
+
+// NewSlogForwardHandler 返回将 [Record] 转发给 h 的 [Handler]
+//
+// 这是 [Logs.SLogHandler] 的反向操作，可以将任意的 [slog.Handler] 作为 [Logs] 的后端。
+//
+// [Level] 与 [slog.Level] 的对应关系如下：
+//   - [LevelTrace]：[slog.LevelDebug]-4；
+//   - [LevelDebug]：[slog.LevelDebug]；
+//   - [LevelInfo]：[slog.LevelInfo]；
+//   - [LevelWarn]：[slog.LevelWarn]；
+//   - [LevelError]：[slog.LevelError]；
+//   - [LevelFatal]：[slog.LevelError]+4；
+//
+// 转发的记录始终带有由 [Record.Time] 指定的时间，与 [WithCreated] 无关。
+//
+// NOTE: 只有 [WithLocation] 为 true 时，转发的记录才会带有 PC 值。
+// 如果 h 返回错误，且未通过 [WithErrorHandler] 指定错误处理函数，会将错误信息输出到终端。
+func NewSlogForwardHandler(h slog.Handler) Handler {
+	return &slogForwardHandler{h: h, lv: slog.LevelInfo}
+}
+
+func logsLevel2Slog(lv Level) slog.Level {
+	switch lv {
+	case LevelTrace:
+		return slog.LevelDebug - 4
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelFatal:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func attr2Slog(a Attr) slog.Attr {
+	if g, ok := a.V.([]Attr); ok {
+		as := make([]slog.Attr, 0, len(g))
+		for _, aa := range g {
+			as = append(as, attr2Slog(aa))
+		}
+		return slog.Attr{Key: a.K, Value: slog.GroupValue(as...)}
+	}
+	return slog.Any(a.K, a.V)
+}
+
+func (h *slogForwardHandler) Handle(e *Record) {
+	ctx := context.Background()
+	if !h.h.Enabled(ctx, h.lv) {
+		return
+	}
+
+	b := NewBuffer(h.detail)
+	defer b.Free()
+	b.AppendFunc(e.AppendMessage)
+
+	r := slog.NewRecord(e.created, h.lv, string(b.Bytes()), e.pc)
+	for _, a := range e.Attrs {
+		r.AddAttrs(attr2Slog(a))
+	}
+
+	if err := h.h.Handle(ctx, r); err != nil && !e.HandleError(h, err) {
+		fmt.Fprintf(os.Stderr, "NewSlogForwardHandler.Handle:%v\n", err)
+	}
+}
+
+func (h *slogForwardHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	hh := h.h
+	if len(attrs) > 0 {
+		as := make([]slog.Attr, 0, len(attrs))
+		for _, a := range attrs {
+			as = append(as, attr2Slog(a))
+		}
+		hh = hh.WithAttrs(as)
+	}
+
+	return &slogForwardHandler{h: hh, lv: logsLevel2Slog(lv), detail: detail}
+}
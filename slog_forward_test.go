@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestNewSlogForwardHandler(t *testing.T) {
+	a := assert.New(t, false)
+
+	buf := new(bytes.Buffer)
+	sh := slog.NewTextHandler(buf, &slog.HandlerOptions{AddSource: true, Level: slog.Level(-8)})
+	l := New(NewSlogForwardHandler(sh), WithLocation(true), WithCreated(MilliLayout), WithAttrs(map[string]any{"a1": 1}))
+
+	l.WARN().With("k1", "v1").With("g1", []Attr{{K: "k2", V: 2}}).String("msg")
+	a.Contains(buf.String(), "level=WARN").
+		Contains(buf.String(), "time=").
+		Contains(buf.String(), "slog_forward_test.go:22").
+		Contains(buf.String(), `msg=msg a1=1 k1=v1 g1.k2=2`)
+
+	buf.Reset()
+	l.TRACE().String("trace")
+	a.Contains(buf.String(), "level=DEBUG-4")
+
+	buf.Reset()
+	l.FATAL().String("fatal")
+	a.Contains(buf.String(), "level=ERROR+4")
+
+	// 由 slog.Handler 过滤
+	buf.Reset()
+	l = New(NewSlogForwardHandler(slog.NewTextHandler(buf, nil)))
+	l.DEBUG().String("debug")
+	a.Empty(buf.String())
+
+	// 未指定 WithCreated 也会传递时间
+	l.INFO().String("info")
+	a.Contains(buf.String(), "time=")
+
+	a.Equal(slogLevel(logsLevel2Slog(LevelTrace)), LevelTrace).
+		Equal(slogLevel(logsLevel2Slog(LevelFatal)), LevelFatal)
+}
@@ -6,12 +6,18 @@ package logs
 
 import (
 	"bytes"
+	"encoding/json"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert/v4"
 )
 
+type logValuer string
+
+func (v logValuer) LogValue() slog.Value { return slog.StringValue("resolved-" + string(v)) }
+
 func TestLogs_WithStd(t *testing.T) {
 	a := assert.New(t, false)
 
@@ -25,7 +31,74 @@ func TestLogs_WithStd(t *testing.T) {
 	l2.Warn("warn")
 	a.Contains(buf.String(), "warn").Contains(buf.String(), "attr1=val1")
 
+	// 分组之前添加的属性不属于该分组
+	buf.Reset()
 	l3 := l2.WithGroup("g1")
-	l3.Warn("group")
-	a.Contains(buf.String(), "g1.attr1=val1")
+	l3.Warn("group", "attr2", "val2")
+	a.Contains(buf.String(), " attr1=val1").
+		Contains(buf.String(), "g1.attr2=val2")
+}
+
+func TestSLogHandler(t *testing.T) {
+	a := assert.New(t, false)
+
+	buf := new(bytes.Buffer)
+	l := New(NewJSONHandler(buf))
+	sl := slog.New(l.SLogHandler())
+
+	sl.With("a1", 1).WithGroup("g1").With("a2", logValuer("v2")).WithGroup("g2").
+		Info("msg", "a3", true, slog.Group("g3", "a4", time.Second), slog.Group("empty"))
+	a.True(json.Valid(buf.Bytes()), buf.String()).
+		Contains(buf.String(), `"attrs":[{"a1":1},{"g1":{"a2":"resolved-v2","g2":{"a3":true,"g3":{"a4":1000000000}}}}]`)
+
+	// 空的分组
+	buf.Reset()
+	sl.WithGroup("g1").WithGroup("g2").Info("msg")
+	a.NotContains(buf.String(), `"attrs"`)
+
+	// 无名称的分组
+	buf.Reset()
+	sl.Info("msg", slog.Group("", "a1", 1))
+	a.Contains(buf.String(), `"attrs":[{"a1":1}]`)
+}
+
+func TestSLogHandler_level(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(slogLevel(slog.Level(-8)), LevelTrace).
+		Equal(slogLevel(slog.LevelDebug), LevelDebug).
+		Equal(slogLevel(slog.LevelDebug+1), LevelDebug).
+		Equal(slogLevel(slog.LevelInfo), LevelInfo).
+		Equal(slogLevel(slog.LevelWarn+2), LevelWarn).
+		Equal(slogLevel(slog.LevelError), LevelError).
+		Equal(slogLevel(slog.LevelError+4), LevelFatal)
+
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf), WithLevels(LevelTrace, LevelFatal))
+	sl := slog.New(l.SLogHandler())
+	a.False(sl.Enabled(t.Context(), slog.LevelInfo)).
+		True(sl.Enabled(t.Context(), slog.Level(-8)))
+
+	sl.Log(t.Context(), slog.LevelError+4, "fatal")
+	a.Contains(buf.String(), "[FATL]")
+}
+
+func TestWithReplaceAttr(t *testing.T) {
+	a := assert.New(t, false)
+
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf), WithReplaceAttr(func(groups []string, attr slog.Attr) slog.Attr {
+		switch attr.Key {
+		case "password":
+			return slog.String(attr.Key, "***")
+		case "remove":
+			return slog.Attr{}
+		case "a2":
+			a.Equal(groups, []string{"g1"})
+		}
+		return attr
+	}))
+	sl := slog.New(l.SLogHandler())
+	sl.With("password", "123").WithGroup("g1").Info("msg", "remove", 1, "a2", 2)
+	a.Contains(buf.String(), "msg password=*** g1.a2=2\n")
 }
@@ -0,0 +1,425 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 日志中 SQL 参数的输出方式
+const (
+	ArgsCount  ArgsMode = iota // 仅输出参数的数量
+	ArgsRedact                 // 仅输出参数的类型
+	ArgsFull                   // 输出参数的值，可能会包含敏感信息，仅建议在开发环境中使用。
+)
+
+type (
+	// ArgsMode SQL 参数在日志中的输出方式
+	ArgsMode int8
+
+	// DriverOptions [NewDriver] 和 [NewConnector] 的配置项
+	DriverOptions struct {
+		// SlowThreshold 慢查询的阈值
+		//
+		// 执行时间超过此值的语句将以 [LevelWarn] 输出，0 表示不启用。
+		SlowThreshold time.Duration
+
+		// Args 参数的输出方式
+		Args ArgsMode
+
+		// Level 正常执行的语句的日志级别
+		//
+		// 默认值为 [LevelInfo]。
+		Level Level
+	}
+
+	sqlLogger struct {
+		lv      Level
+		loggers map[Level]*Logger
+		slow    time.Duration
+		args    ArgsMode
+	}
+
+	sqlDriver struct {
+		d driver.Driver
+		s *sqlLogger
+	}
+
+	sqlConnector struct {
+		c driver.Connector
+		d driver.Driver
+		s *sqlLogger
+	}
+
+	dsnConnector struct {
+		dsn string
+		d   driver.Driver
+	}
+
+	sqlConn struct {
+		c driver.Conn
+		s *sqlLogger
+	}
+
+	sqlStmt struct {
+		st    driver.Stmt
+		query string
+		s     *sqlLogger
+	}
+
+	sqlTx struct {
+		tx  driver.Tx
+		ctx context.Context
+		s   *sqlLogger
+	}
+)
+
+// NewDriver 返回记录每一条 SQL 语句的 [driver.Driver]
+//
+// 语句的内容、参数、影响的行数、执行时间以及错误信息都会以属性的形式通过 l 输出。
+// 执行出错时采用 [LevelError]，慢查询采用 [LevelWarn]，其它情况采用 [DriverOptions.Level]。
+// 如果 l 为 [AttrLogs]，则无论采用哪个级别，日志都带有其属性。
+//
+// o 可以为 nil，表示采用默认值。
+//
+// NOTE: l 需要在返回对象的整个生命周期内保持可用，不能在此期间调用 [FreeAttrLogs]。
+func NewDriver(d driver.Driver, l Loggers, o *DriverOptions) driver.Driver {
+	return &sqlDriver{d: d, s: newSQLLogger(l, o)}
+}
+
+// NewConnector 返回记录每一条 SQL 语句的 [driver.Connector]
+//
+// 参数及功能与 [NewDriver] 相同。
+func NewConnector(c driver.Connector, l Loggers, o *DriverOptions) driver.Connector {
+	s := newSQLLogger(l, o)
+	return &sqlConnector{c: c, d: &sqlDriver{d: c.Driver(), s: s}, s: s}
+}
+
+func newSQLLogger(l Loggers, o *DriverOptions) *sqlLogger {
+	if o == nil {
+		o = &DriverOptions{}
+	}
+	return &sqlLogger{
+		lv:      o.Level,
+		loggers: resolveLoggers(l, o.Level, LevelWarn, LevelError),
+		slow:    o.SlowThreshold,
+		args:    o.Args,
+	}
+}
+
+func (s *sqlLogger) log(ctx context.Context, op, query string, args []driver.NamedValue, start time.Time, rows int64, err error) {
+	if errors.Is(err, driver.ErrSkip) { // 并不是真正的错误，由 database/sql 改用其它方式执行。
+		return
+	}
+
+	dur := time.Since(start)
+
+	lv := s.lv
+	switch {
+	case err != nil:
+		lv = LevelError
+	case s.slow > 0 && dur >= s.slow:
+		lv = LevelWarn
+	}
+	l := s.loggers[lv]
+	if !l.IsEnable() {
+		return
+	}
+
+	r := l.WithContext(ctx).With("op", op)
+	if query != "" {
+		r = r.With("sql", query)
+	}
+	if args != nil {
+		r = r.With("args", s.formatArgs(args))
+	}
+	if rows >= 0 {
+		r = r.With("rows", rows)
+	}
+	r = r.With("duration", dur.String())
+
+	if err != nil {
+		r.Error(err)
+	} else {
+		r.String(op)
+	}
+}
+
+func (s *sqlLogger) formatArgs(args []driver.NamedValue) any {
+	switch s.args {
+	case ArgsRedact:
+		types := make([]string, 0, len(args))
+		for _, arg := range args {
+			types = append(types, fmt.Sprintf("%T", arg.Value))
+		}
+		return types
+	case ArgsFull:
+		vals := make([]any, 0, len(args))
+		for _, arg := range args {
+			vals = append(vals, arg.Value)
+		}
+		return vals
+	default:
+		return len(args)
+	}
+}
+
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{c: c, s: d.s}, nil
+}
+
+func (d *sqlDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.d.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &sqlConnector{c: c, d: d, s: d.s}, nil
+	}
+	return &sqlConnector{c: &dsnConnector{dsn: name, d: d.d}, d: d, s: d.s}, nil
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{c: conn, s: c.s}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver { return c.d }
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open(c.dsn) }
+
+func (c *dsnConnector) Driver() driver.Driver { return c.d }
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var st driver.Stmt
+	var err error
+	if cp, ok := c.c.(driver.ConnPrepareContext); ok {
+		st, err = cp.PrepareContext(ctx, query)
+	} else {
+		st, err = c.c.Prepare(query)
+	}
+
+	if err != nil {
+		c.s.log(ctx, "prepare", query, nil, time.Now(), -1, err)
+		return nil, err
+	}
+	return &sqlStmt{st: st, query: query, s: c.s}, nil
+}
+
+func (c *sqlConn) Close() error { return c.c.Close() }
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+
+	var tx driver.Tx
+	var err error
+	if cb, ok := c.c.(driver.ConnBeginTx); ok {
+		tx, err = cb.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.c.Begin()
+	}
+
+	c.s.log(ctx, "begin", "", nil, start, -1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{tx: tx, ctx: ctx, s: c.s}, nil
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	switch q := c.c.(type) {
+	case driver.QueryerContext:
+		rows, err = q.QueryContext(ctx, query, args)
+	case driver.Queryer:
+		var vals []driver.Value
+		if vals, err = namedValues(args); err == nil {
+			rows, err = q.Query(query, vals)
+		}
+	default:
+		return nil, driver.ErrSkip
+	}
+
+	c.s.log(ctx, "query", query, args, start, -1, err)
+	return rows, err
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var result driver.Result
+	var err error
+	switch e := c.c.(type) {
+	case driver.ExecerContext:
+		result, err = e.ExecContext(ctx, query, args)
+	case driver.Execer:
+		var vals []driver.Value
+		if vals, err = namedValues(args); err == nil {
+			result, err = e.Exec(query, vals)
+		}
+	default:
+		return nil, driver.ErrSkip
+	}
+
+	c.s.log(ctx, "exec", query, args, start, rowsAffected(result, err), err)
+	return result, err
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if p, ok := c.c.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.c.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.c.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(v *driver.NamedValue) error {
+	if nc, ok := c.c.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+func (s *sqlStmt) Close() error { return s.st.Close() }
+
+func (s *sqlStmt) NumInput() int { return s.st.NumInput() }
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), values2Named(args))
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), values2Named(args))
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var result driver.Result
+	var err error
+	if se, ok := s.st.(driver.StmtExecContext); ok {
+		result, err = se.ExecContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = namedValues(args); err == nil {
+			result, err = s.st.Exec(vals)
+		}
+	}
+
+	s.s.log(ctx, "exec", s.query, args, start, rowsAffected(result, err), err)
+	return result, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	if sq, ok := s.st.(driver.StmtQueryContext); ok {
+		rows, err = sq.QueryContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = namedValues(args); err == nil {
+			rows, err = s.st.Query(vals)
+		}
+	}
+
+	s.s.log(ctx, "query", s.query, args, start, -1, err)
+	return rows, err
+}
+
+func (s *sqlStmt) CheckNamedValue(v *driver.NamedValue) error {
+	if nc, ok := s.st.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+func (s *sqlStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := s.st.(driver.ColumnConverter); ok {
+		return cc.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+func (tx *sqlTx) Commit() error {
+	start := time.Now()
+	err := tx.tx.Commit()
+	tx.s.log(tx.ctx, "commit", "", nil, start, -1, err)
+	return err
+}
+
+func (tx *sqlTx) Rollback() error {
+	start := time.Now()
+	err := tx.tx.Rollback()
+	tx.s.log(tx.ctx, "rollback", "", nil, start, -1, err)
+	return err
+}
+
+func rowsAffected(r driver.Result, err error) int64 {
+	if err != nil || r == nil {
+		return -1
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	vals := make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql: 驱动不支持命名参数")
+		}
+		vals = append(vals, arg.Value)
+	}
+	return vals, nil
+}
+
+func values2Named(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, 0, len(args))
+	for i, v := range args {
+		named = append(named, driver.NamedValue{Ordinal: i + 1, Value: v})
+	}
+	return named
+}
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert/v4"
+)
+
+type (
+	fakeDriver struct{}
+
+	fakeConn struct{}
+
+	fakeStmt struct{ query string }
+
+	fakeRows struct{ n int }
+)
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if query == "invalid" {
+		return nil, errors.New("syntax error")
+	}
+	return &fakeStmt{query: query}, nil
+}
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) { return fakeConn{}, nil }
+
+func (fakeConn) Commit() error { return nil }
+
+func (fakeConn) Rollback() error { return errors.New("rollback error") }
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query == "slow" {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) { return &fakeRows{}, nil }
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n > 0 {
+		return io.EOF
+	}
+	r.n++
+	dest[0] = int64(1)
+	return nil
+}
+
+func newTestDB(a *assert.Assertion, l Loggers, o *DriverOptions) *sql.DB {
+	c, err := NewDriver(fakeDriver{}, l, o).(driver.DriverContext).OpenConnector("")
+	a.NotError(err).NotNil(c)
+	return sql.OpenDB(c)
+}
+
+func TestNewDriver(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf)).New(map[string]any{"db": "main"})
+	db := newTestDB(a, l, &DriverOptions{SlowThreshold: 5 * time.Millisecond, Level: LevelDebug})
+	defer db.Close()
+
+	_, err := db.Exec("insert", 1, "2")
+	a.NotError(err).
+		Contains(buf.String(), "[DBUG] exec db=main op=exec sql=insert args=2 rows=2 duration=")
+
+	buf.Reset()
+	rows, err := db.Query("select")
+	a.NotError(err)
+	a.NotError(rows.Close()).
+		Contains(buf.String(), "[DBUG] query db=main op=query sql=select args=0 duration=")
+
+	buf.Reset()
+	_, err = db.ExecContext(context.Background(), "slow")
+	a.NotError(err).
+		Contains(buf.String(), "[WARN] exec db=main op=exec sql=slow")
+
+	buf.Reset()
+	_, err = db.Exec("invalid")
+	a.Error(err).
+		Contains(buf.String(), "[ERRO] syntax error db=main op=prepare sql=invalid")
+
+	buf.Reset()
+	tx, err := db.Begin()
+	a.NotError(err)
+	a.Error(tx.Rollback()).
+		Contains(buf.String(), "[DBUG] begin db=main op=begin").
+		Contains(buf.String(), "[ERRO] rollback error db=main op=rollback")
+}
+
+func TestNewConnector_args(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf))
+
+	c, err := NewDriver(fakeDriver{}, l, nil).(driver.DriverContext).OpenConnector("")
+	a.NotError(err)
+
+	db := sql.OpenDB(NewConnector(c, l, &DriverOptions{Args: ArgsRedact}))
+	_, err = db.Exec("insert", 1, "2")
+	a.NotError(err).
+		Contains(buf.String(), "args=[int64 string]")
+	a.NotError(db.Close())
+
+	buf.Reset()
+	db = newTestDB(a, l, &DriverOptions{Args: ArgsFull})
+	_, err = db.Exec("insert", 1, "2")
+	a.NotError(err).
+		Contains(buf.String(), "args=[1 2]")
+	a.NotError(db.Close())
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"runtime"
+	"slices"
+)
+
+const maxStackDepth = 64
+
+// WithStack 为级别不低于 lv 的日志记录调用堆栈
+//
+// 与 [WithDetail] 不同，堆栈是在输出日志的位置通过 [runtime.Callers] 获取的，
+// 与日志的内容是否为 error 以及 error 是否实现了 xerrors.Formatter 无关。
+// 级别的高低按严重程度比较，依次为 [LevelTrace]、[LevelDebug]、[LevelInfo]、
+// [LevelWarn]、[LevelError] 和 [LevelFatal]。
+//
+// skipPkgs 为需要从堆栈中过滤掉的包的导入路径，比如 net/http，
+// 仅对 [Record.Stack] 的返回值有效，不影响实际记录的内容。
+//
+// [NewTextHandler] 会在日志之后以多行的形式输出堆栈，
+// [NewJSONHandler] 则以 stack 字段输出由各帧组成的数组。
+func WithStack(lv Level, skipPkgs ...string) Option {
+	return func(l *Logs) {
+		l.stack = true
+		l.stackLevel = lv
+		l.stackSkip = skipPkgs
+	}
+}
+
+// StackLevel 记录调用堆栈的最低级别
+//
+// 如果未通过 [WithStack] 启用，则 ok 为 false。
+func (logs *Logs) StackLevel() (lv Level, ok bool) { return logs.stackLevel, logs.stack }
+
+func (logs *Logs) needStack(lv Level) bool {
+	return logs.stack && lv.severity() >= logs.stackLevel.severity()
+}
+
+// 根据 [Record.Level] 决定是否记录调用堆栈
+//
+// depth 表示调用，1 表示调用此方法的位置；
+func (e *Record) captureStack(depth int) {
+	if !e.logs.needStack(e.lv) {
+		return
+	}
+
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(depth+1, pcs[:])
+	e.stack = slices.Clone(pcs[:n])
+}
+
+// 记录以 pc 为起点的调用堆栈
+//
+// pc 为调用方通过 [runtime.Callers] 获取的返回地址，比如 slog.Record.PC，
+// pc 之前的帧会被丢弃，如果当前堆栈中不存在 pc，则以调用此方法的位置为起点。
+func (e *Record) captureStackFrom(pc uintptr) {
+	e.captureStack(2)
+	if i := slices.Index(e.stack, pc); i > 0 {
+		e.stack = e.stack[i:]
+	}
+}
+
+// Stack 日志的调用堆栈
+//
+// 只有通过 [WithStack] 启用了该功能且日志的级别符合要求时才会有值，
+// 第一个元素即为输出日志的位置，由 [WithStack] 指定的包会被过滤掉。
+func (e *Record) Stack() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+
+	frames := make([]runtime.Frame, 0, len(e.stack))
+	fs := runtime.CallersFrames(e.stack)
+	for {
+		f, more := fs.Next()
+		if !slices.Contains(e.logs.stackSkip, funcPackage(f.Function)) {
+			frames = append(frames, f)
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// 以多行的形式输出堆栈，每一帧占两行，格式与 panic 时输出的堆栈相同。
+func appendTextStack(b *Buffer, frames []runtime.Frame) {
+	for _, f := range frames {
+		b.AppendString("\n\t").AppendString(f.Function).
+			AppendString("\n\t\t").AppendString(f.File).
+			AppendBytes(':').AppendInt(int64(f.Line), 10)
+	}
+}
+
+// 以 [{"function":"","file":"","line":0}] 的形式输出堆栈
+func appendJSONStack(b *Buffer, frames []runtime.Frame) {
+	b.AppendBytes('[')
+	for i, f := range frames {
+		if i > 0 {
+			b.AppendBytes(',')
+		}
+		b.AppendString(`{"function":`)
+		appendJSONString(b, f.Function)
+		b.AppendString(`,"file":`)
+		appendJSONString(b, f.File)
+		b.AppendString(`,"line":`).AppendInt(int64(f.Line), 10).AppendBytes('}')
+	}
+	b.AppendBytes(']')
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestWithStack(t *testing.T) {
+	a := assert.New(t, false)
+
+	var frames []runtime.Frame
+	h := handleFunc(func(e *Record) { frames = e.Freeze().Stack() })
+	l := New(h, WithStack(LevelWarn, "testing"))
+	lv, ok := l.StackLevel()
+	a.True(ok).Equal(lv, LevelWarn)
+
+	l.INFO().String("info")
+	a.Empty(frames)
+
+	l.ERROR().Error(errors.New("error"))
+	a.NotEmpty(frames).
+		Equal(frames[0].Function, "github.com/issue9/logs/v7.TestWithStack").
+		True(strings.HasSuffix(frames[0].File, "/stack_test.go"))
+	for _, f := range frames {
+		a.NotEqual(funcPackage(f.Function), "testing")
+	}
+
+	l.WARN().With("k", "v").String("warn")
+	a.NotEmpty(frames).Equal(frames[0].Function, "github.com/issue9/logs/v7.TestWithStack")
+
+	// 由 Logs.NewRecord 创建
+	l.NewRecord().DepthString(1, "record").Output(l.FATAL())
+	a.NotEmpty(frames).Equal(frames[0].Function, "github.com/issue9/logs/v7.TestWithStack")
+
+	// slog
+	frames = nil
+	slog.New(l.SLogHandler()).Error("slog")
+	a.NotEmpty(frames).Equal(frames[0].Function, "github.com/issue9/logs/v7.TestWithStack")
+
+	// 未启用
+	l = New(h)
+	_, ok = l.StackLevel()
+	a.False(ok)
+	l.FATAL().String("fatal")
+	a.Empty(frames)
+}
+
+func TestWithStack_handler(t *testing.T) {
+	a := assert.New(t, false)
+
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf), WithStack(LevelError, "testing", "runtime"))
+	l.ERROR().With("k", "v").String("error")
+	l.INFO().String("info")
+	out := buf.String()
+	a.True(strings.HasPrefix(out, "[ERRO] error k=v\n\tgithub.com/issue9/logs/v7.TestWithStack_handler\n\t\t"), out).
+		True(strings.HasSuffix(out, "\n[INFO] info\n"), out).
+		Equal(strings.Count(out, "\n\t"), 2, out) // 仅剩下 TestWithStack_handler 的两行
+
+	buf.Reset()
+	l = New(NewJSONHandler(buf), WithStack(LevelError, "testing", "runtime"))
+	l.ERROR().String("error")
+	out = buf.String()
+	a.True(strings.HasPrefix(out, `{"level":"ERRO","message":"error","stack":[{"function":"github.com/issue9/logs/v7.TestWithStack_handler","file":"`), out).
+		True(strings.HasSuffix(out, `}]}`), out)
+}
+
+func TestAppendJSONStack(t *testing.T) {
+	a := assert.New(t, false)
+
+	b := NewBuffer(false)
+	defer b.Free()
+	appendJSONStack(b, []runtime.Frame{{Function: "main.main", File: `C:\app\"main".go`, Line: 5}})
+	a.True(json.Valid(b.Bytes())).
+		Equal(string(b.Bytes()), `[{"function":"main.main","file":"C:\\app\\\"main\".go","line":5}]`)
+}
+
+func TestFuncPackage(t *testing.T) {
+	a := assert.New(t, false)
+
+	a.Equal(funcPackage("gopkg.in/yaml%2ev3.Marshal"), "gopkg.in/yaml.v3").
+		Equal(funcPackage("gopkg.in/yaml%2ev3.(*Encoder).Encode"), "gopkg.in/yaml.v3").
+		Equal(funcPackage("main.main"), "main")
+}
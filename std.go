@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import "strings"
+
+// 标准库 log 包输出内容中可识别的级别名称
+var stdLevels = map[string]Level{
+	"TRACE":    LevelTrace,
+	"TRAC":     LevelTrace,
+	"DEBUG":    LevelDebug,
+	"DBUG":     LevelDebug,
+	"INFO":     LevelInfo,
+	"WARN":     LevelWarn,
+	"WARNING":  LevelWarn,
+	"ERROR":    LevelError,
+	"ERRO":     LevelError,
+	"ERR":      LevelError,
+	"FATAL":    LevelFatal,
+	"FATL":     LevelFatal,
+	"PANIC":    LevelFatal,
+	"CRIT":     LevelFatal,
+	"CRITICAL": LevelFatal,
+}
+
+// 接管标准库 log 包的输出
+type stdWriter struct {
+	l *Logs
+}
+
+func (w *stdWriter) Write(data []byte) (int, error) {
+	lv, msg := parseStdLevel(strings.TrimRight(string(data), "\r\n"))
+	if w.l.IsEnable(lv) {
+		// 0 为 initLocationCreated，5 为调用 log.Print 等函数的位置。
+		l := w.l.Logger(lv)
+		l.newRecord().DepthString(5, msg).Output(l)
+	}
+	return len(data), nil
+}
+
+// 从 log.Print 等函数的输出内容中分析出日志级别
+//
+// 支持 [ERROR]、WARN: 以及 level=debug 等形式，返回的 msg 中会去掉这些表示级别的内容。
+// 无法识别的内容均作为 [LevelInfo]。
+func parseStdLevel(msg string) (Level, string) {
+	s := strings.TrimLeft(msg, " \t")
+
+	// [ERROR] msg
+	if strings.HasPrefix(s, "[") {
+		if end := strings.IndexByte(s, ']'); end > 0 {
+			if lv, found := stdLevels[strings.ToUpper(s[1:end])]; found {
+				return lv, strings.TrimLeft(s[end+1:], " \t")
+			}
+		}
+	}
+
+	// ERROR: msg
+	if end := strings.IndexByte(s, ':'); end > 0 {
+		if lv, found := stdLevels[strings.ToUpper(s[:end])]; found {
+			return lv, strings.TrimLeft(s[end+1:], " \t")
+		}
+	}
+
+	// level=error msg
+	for i := 0; ; {
+		index := strings.Index(s[i:], "level=")
+		if index < 0 {
+			break
+		}
+		start := i + index
+		i = start + len("level=")
+
+		if start > 0 && s[start-1] != ' ' && s[start-1] != '\t' {
+			continue
+		}
+
+		end := strings.IndexAny(s[i:], " \t")
+		if end < 0 {
+			end = len(s)
+		} else {
+			end += i
+		}
+
+		if lv, found := stdLevels[strings.ToUpper(strings.Trim(s[i:end], `"`))]; found {
+			// 去掉 level=xx 之后，前后两部分以一个空格连接。
+			before, after := strings.TrimRight(s[:start], " \t"), strings.TrimLeft(s[end:], " \t")
+			if before == "" || after == "" {
+				return lv, before + after
+			}
+			return lv, before + " " + after
+		}
+	}
+
+	return LevelInfo, msg
+}
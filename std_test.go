@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestParseStdLevel(t *testing.T) {
+	a := assert.New(t, false)
+
+	data := []struct {
+		input string
+		lv    Level
+		msg   string
+	}{
+		{input: "msg", lv: LevelInfo, msg: "msg"},
+		{input: "[ERROR] msg", lv: LevelError, msg: "msg"},
+		{input: " [warn]msg", lv: LevelWarn, msg: "msg"},
+		{input: "[abc] msg", lv: LevelInfo, msg: "[abc] msg"},
+		{input: "WARN: msg", lv: LevelWarn, msg: "msg"},
+		{input: "Debug:msg", lv: LevelDebug, msg: "msg"},
+		{input: "http: msg", lv: LevelInfo, msg: "http: msg"},
+		{input: "level=debug msg", lv: LevelDebug, msg: "msg"},
+		{input: `msg level="fatal" k=v`, lv: LevelFatal, msg: "msg k=v"},
+		{input: "msg sublevel=error", lv: LevelInfo, msg: "msg sublevel=error"},
+		{input: "msg level=abc", lv: LevelInfo, msg: "msg level=abc"},
+	}
+
+	for _, item := range data {
+		lv, msg := parseStdLevel(item.input)
+		a.Equal(lv, item.lv, item.input).Equal(msg, item.msg, item.input)
+	}
+}
+
+func TestWithStd_log(t *testing.T) {
+	a := assert.New(t, false)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf), WithLocation(true), WithStd(), WithLevels(LevelInfo, LevelError))
+
+	log.Print("[ERROR] error msg")
+	a.Contains(buf.String(), "[ERRO] ").
+		Contains(buf.String(), "std_test.go:53").
+		Contains(buf.String(), "\terror msg\n")
+
+	buf.Reset()
+	log.Printf("level=%s %s", "info", "info msg")
+	a.Contains(buf.String(), "[INFO] ").
+		Contains(buf.String(), "std_test.go:59").
+		Contains(buf.String(), "\tinfo msg\n")
+
+	// 未启用
+	buf.Reset()
+	log.Println("WARN: warn msg")
+	a.Empty(buf.String())
+
+	l.Enable(AllLevels()...)
+	log.Println("WARN: warn msg")
+	a.Contains(buf.String(), "[WARN] ").
+		Contains(buf.String(), "std_test.go:70").
+		Contains(buf.String(), "\twarn msg\n")
+}
+
+func TestParseStdLevel_space(t *testing.T) {
+	a := assert.New(t, false)
+
+	lv, msg := parseStdLevel("msg\tlevel=warn \t k=v")
+	a.Equal(lv, LevelWarn).Equal(msg, "msg k=v")
+
+	lv, msg = parseStdLevel("msg level=error ")
+	a.Equal(lv, LevelError).Equal(msg, "msg")
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"slices"
+	"sync"
+)
+
+type (
+	// TailOptions [Logs.NewTail] 的配置项
+	TailOptions struct {
+		// Levels 需要缓存的日志级别
+		//
+		// 默认为 [LevelDebug] 和 [LevelTrace]。
+		Levels []Level
+
+		// MaxCount 最多缓存的日志数量
+		//
+		// 超出之后会丢弃最早的日志，默认值为 100。
+		MaxCount int
+
+		// MaxBytes 最多缓存的日志大小
+		//
+		// 以日志的消息和属性的长度作为日志的大小，超出之后会丢弃最早的日志，默认值为 64KB。
+		MaxBytes int
+	}
+
+	tailBuffer struct {
+		mux       sync.Mutex
+		levels    []Level
+		maxCount  int
+		maxBytes  int
+		size      int
+		items     []tailItem
+		triggered bool // 已经输出过错误日志，之后的日志不再缓存。
+	}
+
+	tailItem struct {
+		h    Handler
+		r    *Record
+		size int
+	}
+
+	// 缓存日志的 Handler
+	tailHandler struct {
+		h Handler
+		t *tailBuffer
+	}
+
+	// 在输出日志之前先输出缓存日志的 Handler
+	tailTrigger struct {
+		h Handler
+		t *tailBuffer
+	}
+)
+
+// NewTail 声明一组带有 attrs 属性且会缓存低级别日志的日志对象
+//
+// 返回对象中由 o.Levels 指定级别的日志不会直接输出，而是缓存在内存中，
+// 直到同一对象输出 [LevelError] 或是 [LevelFatal] 级别的日志时，才会在其之前一次性输出，
+// 此后这些级别的日志也将直接输出。
+// 如果在此之前调用了 [FreeAttrLogs]，则缓存的日志会被丢弃。
+// 也可以通过 [AttrLogs.Flush] 主动输出缓存的日志。
+//
+// 适用于每个请求或是任务声明一个对象的场景，可以在不输出所有调试日志的前提下，
+// 保留出错时的完整上下文。
+// o.Levels 指定的级别即使未通过 [Logs.Enable] 启用，也会被缓存。
+//
+// o 可以为 nil，表示采用默认值。
+func (logs *Logs) NewTail(attrs map[string]any, o *TailOptions) *AttrLogs {
+	if o == nil {
+		o = &TailOptions{}
+	}
+
+	t := &tailBuffer{
+		levels:   o.Levels,
+		maxCount: o.MaxCount,
+		maxBytes: o.MaxBytes,
+	}
+	if len(t.levels) == 0 {
+		t.levels = []Level{LevelDebug, LevelTrace}
+	}
+	if t.maxCount <= 0 {
+		t.maxCount = 100
+	}
+	if t.maxBytes <= 0 {
+		t.maxBytes = 64 * 1024
+	}
+
+	l := logs.New(attrs)
+	l.tail = t
+	return l
+}
+
+// Flush 输出由 [Logs.NewTail] 缓存的日志
+//
+// 如果 logs 并不是由 [Logs.NewTail] 生成的，则不作任何处理。
+func (logs *AttrLogs) Flush() {
+	if logs.tail != nil {
+		logs.tail.flush()
+	}
+}
+
+func (t *tailBuffer) isBuffered(lv Level) bool { return slices.Index(t.levels, lv) >= 0 }
+
+// 根据 l 的级别替换其 Handler
+func (t *tailBuffer) wrap(l *Logger) {
+	switch {
+	case t.isBuffered(l.Level()):
+		l.force = true
+		l.h = &tailHandler{h: l.h, t: t}
+	case l.Level() == LevelError || l.Level() == LevelFatal:
+		l.h = &tailTrigger{h: l.h, t: t}
+	}
+}
+
+func (t *tailBuffer) push(h Handler, e *Record) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.triggered {
+		return false
+	}
+
+	r := e.Freeze()
+
+	b := NewBuffer(false)
+	appendRecordText(b, r)
+	size := b.Len()
+	b.Free()
+
+	t.items = append(t.items, tailItem{h: h, r: r, size: size})
+	t.size += size
+
+	var n int // 需要删除的数量
+	for size := t.size; n < len(t.items)-1 && (len(t.items)-n > t.maxCount || size > t.maxBytes); n++ {
+		size -= t.items[n].size
+	}
+	if n > 0 {
+		for _, item := range t.items[:n] {
+			t.size -= item.size
+		}
+		t.items = slices.Delete(t.items, 0, n)
+	}
+
+	return true
+}
+
+func (t *tailBuffer) flush() {
+	t.mux.Lock()
+	items := t.items
+	t.items = nil
+	t.size = 0
+	t.triggered = true
+	t.mux.Unlock()
+
+	for _, item := range items {
+		item.h.Handle(item.r)
+	}
+}
+
+func (t *tailBuffer) discard() {
+	t.mux.Lock()
+	t.items = nil
+	t.size = 0
+	t.mux.Unlock()
+}
+
+func (h *tailHandler) Handle(e *Record) {
+	if !h.t.push(h.h, e) {
+		h.h.Handle(e)
+	}
+}
+
+func (h *tailHandler) New(detail bool, lv Level, attrs []Attr) Handler {
+	return &tailHandler{h: h.h.New(detail, lv, attrs), t: h.t}
+}
+
+func (h *tailTrigger) Handle(e *Record) {
+	h.t.flush()
+	h.h.Handle(e)
+}
+
+func (h *tailTrigger) New(detail bool, lv Level, attrs []Attr) Handler {
+	return &tailTrigger{h: h.h.New(detail, lv, attrs), t: h.t}
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+func TestLogs_NewTail(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf))
+	l.Enable(LevelInfo, LevelError)
+
+	// 正常结束
+	tl := l.NewTail(map[string]any{"id": 1}, nil)
+	a.True(tl.IsEnable(LevelDebug)).
+		True(tl.DEBUG().IsEnable()).
+		False(tl.WARN().IsEnable()).
+		False(l.DEBUG().IsEnable())
+	tl.DEBUG().String("debug")
+	tl.TRACE().With("k", "v").String("trace")
+	tl.INFO().String("info")
+	tl.WARN().String("warn")
+	a.Equal(buf.String(), "[INFO] info id=1\n")
+	FreeAttrLogs(tl)
+	a.Equal(buf.String(), "[INFO] info id=1\n")
+
+	// 出错
+	buf.Reset()
+	tl = l.NewTail(map[string]any{"id": 2}, nil)
+	tl.DEBUG().String("debug")
+	tl.TRACE().With("k", "v").String("trace")
+	tl.INFO().String("info")
+	tl.ERROR().String("error")
+	tl.DEBUG().String("after error")
+	a.Equal(buf.String(), "[INFO] info id=2\n[DBUG] debug id=2\n[TRAC] trace id=2 k=v\n[ERRO] error id=2\n[DBUG] after error id=2\n")
+	FreeAttrLogs(tl)
+
+	// 普通的 AttrLogs 不受影响
+	buf.Reset()
+	al := l.New(map[string]any{"id": 3})
+	a.False(al.IsEnable(LevelDebug))
+	al.DEBUG().String("debug")
+	al.Flush()
+	a.Empty(buf.String())
+	FreeAttrLogs(al)
+}
+
+func TestLogs_NewTail_limit(t *testing.T) {
+	a := assert.New(t, false)
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf))
+
+	tl := l.NewTail(nil, &TailOptions{MaxCount: 2, Levels: []Level{LevelInfo}})
+	tl.INFO().String("1")
+	tl.INFO().String("2")
+	tl.INFO().String("3")
+	tl.DEBUG().String("debug")
+	a.Equal(buf.String(), "[DBUG] debug\n")
+	tl.Flush()
+	a.Equal(buf.String(), "[DBUG] debug\n[INFO] 2\n[INFO] 3\n")
+	FreeAttrLogs(tl)
+
+	buf.Reset()
+	tl = l.NewTail(nil, &TailOptions{MaxBytes: 10})
+	tl.DEBUG().String("12345")
+	tl.DEBUG().String("67890")
+	tl.DEBUG().String("abcdef")
+	tl.FATAL().String("fatal")
+	a.Equal(buf.String(), "[DBUG] abcdef\n[FATL] fatal\n")
+	FreeAttrLogs(tl)
+}
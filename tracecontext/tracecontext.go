@@ -0,0 +1,275 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+// Package tracecontext 提供对 [W3C trace-context] 的简单支持
+//
+// 可以在不引入 OpenTelemetry 的情况下，将日志与链路追踪数据进行关联：
+//
+//	l := logs.New(h, logs.WithContextExtractors(tracecontext.Extractor))
+//	ctx := tracecontext.NewContext(r.Context(), tracecontext.Extract(r.Header))
+//	l.INFO().WithContext(ctx).String("msg") // 自动带上 trace_id、span_id 和 trace_flags
+//
+// [W3C trace-context]: https://www.w3.org/TR/trace-context/
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/issue9/logs/v7"
+)
+
+// 报头名称
+const (
+	TraceParentHeader = "Traceparent"
+	TraceStateHeader  = "Tracestate"
+)
+
+// FlagSampled 表示被采样的标记位
+const FlagSampled byte = 0x01
+
+const maxStateMembers = 32
+
+type contextKey int
+
+const traceContextKey contextKey = 0
+
+// TraceContext 链路追踪的上下文信息
+type TraceContext struct {
+	Parent TraceParent
+	State  TraceState
+}
+
+// TraceParent 表示 traceparent 报头的内容
+type TraceParent struct {
+	Version byte
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+// TraceState 表示 tracestate 报头的内容
+//
+// 按报头中的顺序保存，最新修改的在最前面。
+type TraceState []Member
+
+// Member [TraceState] 中的单个键值对
+type Member struct {
+	Key   string
+	Value string
+}
+
+var errInvalidTraceParent = errors.New("无效的 traceparent 格式")
+
+// New 生成新的 [TraceParent]
+//
+// 生成的对象带有 [FlagSampled] 标记。
+func New() TraceParent {
+	p := TraceParent{Flags: FlagSampled}
+	rand.Read(p.TraceID[:])
+	rand.Read(p.SpanID[:])
+	return p
+}
+
+// Parse 解析 traceparent 报头的内容
+func Parse(s string) (TraceParent, error) {
+	var p TraceParent
+
+	// version-traceid-spanid-flags
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return p, errInvalidTraceParent
+	}
+
+	var b [1]byte
+	if err := decodeHex(b[:], s[0:2]); err != nil {
+		return p, err
+	}
+	p.Version = b[0]
+	if p.Version == 0xff || (p.Version == 0 && len(s) != 55) || (len(s) > 55 && s[55] != '-') {
+		return p, errInvalidTraceParent
+	}
+
+	if err := decodeHex(p.TraceID[:], s[3:35]); err != nil {
+		return p, err
+	}
+	if err := decodeHex(p.SpanID[:], s[36:52]); err != nil {
+		return p, err
+	}
+	if err := decodeHex(b[:], s[53:55]); err != nil {
+		return p, err
+	}
+	p.Flags = b[0]
+
+	if !p.IsValid() {
+		return p, errInvalidTraceParent
+	}
+	return p, nil
+}
+
+func decodeHex(dst []byte, s string) error {
+	for i := 0; i < len(s); i++ { // 规范要求只能是小写字母
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return errInvalidTraceParent
+		}
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// IsValid 是否为有效的值
+//
+// TraceID 和 SpanID 均不能为全零。
+func (p TraceParent) IsValid() bool {
+	return p.TraceID != [16]byte{} && p.SpanID != [8]byte{}
+}
+
+// Sampled 是否带有 [FlagSampled] 标记
+func (p TraceParent) Sampled() bool { return p.Flags&FlagSampled == FlagSampled }
+
+// NewChild 生成同一链路中的下一个 span
+func (p TraceParent) NewChild() TraceParent {
+	rand.Read(p.SpanID[:])
+	return p
+}
+
+// TraceIDString 以 32 位小写十六进制的形式返回 TraceID
+func (p TraceParent) TraceIDString() string { return hex.EncodeToString(p.TraceID[:]) }
+
+// SpanIDString 以 16 位小写十六进制的形式返回 SpanID
+func (p TraceParent) SpanIDString() string { return hex.EncodeToString(p.SpanID[:]) }
+
+// FlagsString 以 2 位小写十六进制的形式返回 Flags
+func (p TraceParent) FlagsString() string { return hex.EncodeToString([]byte{p.Flags}) }
+
+// String 转换为 traceparent 报头的格式
+func (p TraceParent) String() string {
+	// 只输出当前版本支持的字段，所以版本号固定为 00。
+	return "00-" + p.TraceIDString() + "-" + p.SpanIDString() + "-" + p.FlagsString()
+}
+
+// ParseState 解析 tracestate 报头的内容
+//
+// 无效的键值对会被忽略，超过 32 个的键值对也会被忽略。
+func ParseState(s string) TraceState {
+	var ts TraceState
+	for item := range strings.SplitSeq(s, ",") {
+		if len(ts) >= maxStateMembers {
+			break
+		}
+
+		item = strings.TrimSpace(item)
+		k, v, found := strings.Cut(item, "=")
+		if !found || k == "" || v == "" || strings.ContainsAny(k, " \t") {
+			continue
+		}
+		ts = append(ts, Member{Key: k, Value: v})
+	}
+	return ts
+}
+
+// Get 获取指定键名的值
+func (ts TraceState) Get(key string) string {
+	for _, m := range ts {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+// Set 设置键值
+//
+// 根据规范，修改或是添加的键值对会被放在最前面。
+func (ts TraceState) Set(key, val string) TraceState {
+	s := make(TraceState, 0, len(ts)+1)
+	s = append(s, Member{Key: key, Value: val})
+	for _, m := range ts {
+		if m.Key != key {
+			s = append(s, m)
+		}
+	}
+
+	if len(s) > maxStateMembers {
+		s = s[:maxStateMembers]
+	}
+	return s
+}
+
+// String 转换为 tracestate 报头的格式
+func (ts TraceState) String() string {
+	var b strings.Builder
+	for i, m := range ts {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(m.Key)
+		b.WriteByte('=')
+		b.WriteString(m.Value)
+	}
+	return b.String()
+}
+
+// NewContext 将 tc 保存至 ctx
+func NewContext(ctx context.Context, tc *TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey, tc)
+}
+
+// FromContext 从 ctx 中获取由 [NewContext] 保存的值
+//
+// 如果不存在，返回 nil。
+func FromContext(ctx context.Context) *TraceContext {
+	if tc, ok := ctx.Value(traceContextKey).(*TraceContext); ok {
+		return tc
+	}
+	return nil
+}
+
+// Extract 从报头中提取链路信息
+//
+// 如果报头中不存在有效的 traceparent，则会调用 [New] 生成新的链路。
+func Extract(h http.Header) *TraceContext {
+	p, err := Parse(h.Get(TraceParentHeader))
+	if err != nil {
+		return &TraceContext{Parent: New()}
+	}
+
+	return &TraceContext{
+		Parent: p,
+		State:  ParseState(strings.Join(h.Values(TraceStateHeader), ",")),
+	}
+}
+
+// Inject 将 ctx 中的链路信息写入报头
+//
+// 如果 ctx 中不存在链路信息，则不作任何操作。
+func Inject(ctx context.Context, h http.Header) {
+	tc := FromContext(ctx)
+	if tc == nil {
+		return
+	}
+
+	h.Set(TraceParentHeader, tc.Parent.String())
+	if len(tc.State) > 0 {
+		h.Set(TraceStateHeader, tc.State.String())
+	} else {
+		h.Del(TraceStateHeader)
+	}
+}
+
+// Extractor 将 ctx 中的链路信息添加到日志
+//
+// 可作为 [logs.WithContextExtractors] 的参数，
+// 会向日志添加 [logs.TraceIDKey]、[logs.SpanIDKey] 和 [logs.TraceFlagsKey] 三个属性。
+func Extractor(ctx context.Context, r *logs.Record) {
+	if tc := FromContext(ctx); tc != nil {
+		r.With(logs.TraceIDKey, tc.Parent.TraceIDString()).
+			With(logs.SpanIDKey, tc.Parent.SpanIDString()).
+			With(logs.TraceFlagsKey, tc.Parent.FlagsString())
+	}
+}
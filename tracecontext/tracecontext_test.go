@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package tracecontext
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+
+	"github.com/issue9/logs/v7"
+)
+
+func TestParse(t *testing.T) {
+	a := assert.New(t, false)
+
+	const tp = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	p, err := Parse(tp)
+	a.NotError(err).
+		Equal(p.Version, 0).
+		Equal(p.TraceIDString(), "4bf92f3577b34da6a3ce929d0e0e4736").
+		Equal(p.SpanIDString(), "00f067aa0ba902b7").
+		True(p.Sampled()).
+		Equal(p.String(), tp)
+
+	// 高版本可以有更多的字段
+	p, err = Parse("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-abc")
+	a.NotError(err).Equal(p.Version, 1).False(p.Sampled())
+
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-abc",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+	} {
+		_, err = Parse(v)
+		a.Error(err, v)
+	}
+}
+
+func TestNew(t *testing.T) {
+	a := assert.New(t, false)
+
+	p := New()
+	a.True(p.IsValid()).True(p.Sampled())
+
+	p2, err := Parse(p.String())
+	a.NotError(err).Equal(p2, p)
+
+	c := p.NewChild()
+	a.Equal(c.TraceID, p.TraceID).NotEqual(c.SpanID, p.SpanID)
+}
+
+func TestTraceState(t *testing.T) {
+	a := assert.New(t, false)
+
+	ts := ParseState("rojo=00f067aa0ba902b7, congo=t61rcWkgMzE,invalid, =v")
+	a.Length(ts, 2).
+		Equal(ts.Get("congo"), "t61rcWkgMzE").
+		Equal(ts.String(), "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE")
+
+	ts = ts.Set("congo", "v2")
+	a.Equal(ts.String(), "congo=v2,rojo=00f067aa0ba902b7")
+}
+
+func TestExtractInject(t *testing.T) {
+	a := assert.New(t, false)
+
+	h := http.Header{}
+	h.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.Set(TraceStateHeader, "rojo=00f067aa0ba902b7")
+	tc := Extract(h)
+	a.Equal(tc.Parent.TraceIDString(), "4bf92f3577b34da6a3ce929d0e0e4736").
+		Equal(tc.State.Get("rojo"), "00f067aa0ba902b7")
+
+	out := http.Header{}
+	Inject(context.Background(), out)
+	a.Empty(out.Get(TraceParentHeader))
+
+	Inject(NewContext(context.Background(), tc), out)
+	a.Equal(out.Get(TraceParentHeader), h.Get(TraceParentHeader)).
+		Equal(out.Get(TraceStateHeader), h.Get(TraceStateHeader))
+
+	// 无效的报头
+	tc = Extract(http.Header{})
+	a.True(tc.Parent.IsValid())
+}
+
+func TestExtractor(t *testing.T) {
+	a := assert.New(t, false)
+
+	tc := &TraceContext{}
+	tc.Parent, _ = Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := NewContext(context.Background(), tc)
+
+	buf := new(bytes.Buffer)
+	l := logs.New(logs.NewTextHandler(buf), logs.WithContextExtractors(Extractor))
+	l.INFO().WithContext(ctx).With("k1", "v1").String("msg")
+	a.Equal(buf.String(), "[INFO] trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 trace_flags=01\tmsg k1=v1\n")
+
+	buf.Reset()
+	l = logs.New(logs.NewJSONHandler(buf), logs.WithContextExtractors(Extractor))
+	l.INFO().WithContext(ctx).With("k1", "v1").String("msg")
+	a.Equal(buf.String(), `{"level":"INFO","message":"msg","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01","attrs":[{"k1":"v1"}]}`)
+
+	buf.Reset()
+	l.INFO().WithContext(ctx).String("msg")
+	a.Equal(buf.String(), `{"level":"INFO","message":"msg","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01"}`)
+}
@@ -0,0 +1,276 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redactedValue = "***"
+
+// DefaultRedactQuery 默认需要隐藏值的查询参数
+var DefaultRedactQuery = []string{"access_token", "api_key", "apikey", "key", "password", "secret", "sig", "signature", "token"}
+
+var redactHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+type (
+	// TransportOptions [NewTransport] 的配置项
+	TransportOptions struct {
+		// RedactQuery 需要隐藏值的查询参数名称
+		//
+		// 不区分大小写，如果为空，则采用 [DefaultRedactQuery]。
+		RedactQuery []string
+
+		// Headers 是否记录请求和响应的报头
+		//
+		// Authorization、Cookie 等报头的值始终是隐藏的。
+		Headers bool
+
+		// Level 请求成功时日志的级别
+		//
+		// 默认值为 [LevelInfo]。
+		Level Level
+
+		// MaxBodySize 记录请求和响应内容的最大字节数
+		//
+		// 0 表示不记录内容。
+		// 响应的内容是在调用者读取的同时记录的，所以会在关闭响应的 Body 时，
+		// 以与请求日志相同的级别单独输出一条日志，如果调用者未关闭 Body，则不会输出。
+		MaxBodySize int
+
+		// RequestIDHeader 请求 ID 的报头名称
+		//
+		// 如果请求的 [context.Context] 中包含由 [NewMiddleware] 保存的请求 ID，
+		// 且请求中不存在该报头，则将请求 ID 写入该报头。
+		// 为空表示采用 [RequestIDHeader]。
+		RequestIDHeader string
+
+		// Propagators 将 [context.Context] 中的数据写入请求报头的函数
+		//
+		// 比如 tracecontext.Inject 可以将链路信息写入请求报头。
+		Propagators []func(context.Context, http.Header)
+	}
+
+	transport struct {
+		next        http.RoundTripper
+		lv          Level
+		loggers     map[Level]*Logger
+		redact      []string
+		headers     bool
+		maxBody     int
+		idKey       string
+		propagators []func(context.Context, http.Header)
+	}
+
+	readCloser struct {
+		io.Reader
+		io.Closer
+	}
+
+	// 在调用者读取的同时记录最多 max 字节的内容，并在关闭时调用 done。
+	teeBody struct {
+		body io.ReadCloser
+		mux  sync.Mutex
+		data []byte
+		max  int
+		done func([]byte)
+	}
+)
+
+// NewTransport 返回记录每一次请求的 [http.RoundTripper]
+//
+// next 为实际执行请求的对象，如果为 nil，则采用 [http.DefaultTransport]；
+// o 可以为 nil，表示采用默认值。
+//
+// 日志的级别根据请求的结果决定：状态码为 4xx 时采用 [LevelWarn]，
+// 状态码为 5xx 或是请求出错时采用 [LevelError]，其它情况采用 [TransportOptions.Level]。
+// 各级别的 [Logger] 在此函数中即从 l 中获取，如果 l 为 [AttrLogs]，则所有日志都带有其属性。
+//
+// NOTE: l 需要在返回对象的整个生命周期内保持可用，不能在此期间调用 [FreeAttrLogs]。
+func NewTransport(next http.RoundTripper, l Loggers, o *TransportOptions) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if o == nil {
+		o = &TransportOptions{}
+	}
+
+	t := &transport{
+		next:        next,
+		lv:          o.Level,
+		loggers:     resolveLoggers(l, o.Level, LevelWarn, LevelError),
+		redact:      o.RedactQuery,
+		headers:     o.Headers,
+		maxBody:     o.MaxBodySize,
+		idKey:       o.RequestIDHeader,
+		propagators: o.Propagators,
+	}
+	if len(t.redact) == 0 {
+		t.redact = DefaultRedactQuery
+	}
+	if t.idKey == "" {
+		t.idKey = RequestIDHeader
+	}
+	return t
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+	r = r.Clone(ctx) // RoundTripper 不应该修改原始的请求
+
+	if id := RequestIDFromContext(ctx); id != "" && r.Header.Get(t.idKey) == "" {
+		r.Header.Set(t.idKey, id)
+	}
+	for _, p := range t.propagators {
+		p(ctx, r.Header)
+	}
+
+	var reqBody []byte
+	if t.maxBody > 0 && r.Body != nil && r.Body != http.NoBody {
+		reqBody, r.Body = peekBody(r.Body, t.maxBody)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(r)
+	latency := time.Since(start)
+
+	lv := t.lv
+	switch {
+	case err != nil || resp.StatusCode >= 500:
+		lv = LevelError
+	case resp.StatusCode >= 400:
+		lv = LevelWarn
+	}
+
+	l := t.loggers[lv]
+	if !l.IsEnable() {
+		return resp, err
+	}
+
+	u := t.redactURL(r.URL)
+	rr := l.WithContext(ctx).
+		With("method", r.Method).
+		With("url", u)
+	if t.headers {
+		rr = rr.With("req_headers", redactHeader(r.Header))
+	}
+	if reqBody != nil {
+		rr = rr.With("req_body", string(reqBody))
+	}
+
+	if err != nil {
+		rr.With("latency", latency.String()).Error(err)
+		return resp, err
+	}
+
+	rr = rr.With("status", resp.StatusCode).With("latency", latency.String())
+	if t.headers {
+		rr = rr.With("resp_headers", redactHeader(resp.Header))
+	}
+	rr.String("http client")
+
+	if t.maxBody > 0 && resp.Body != nil && resp.Body != http.NoBody {
+		// 不能在此处读取内容，否则对于 SSE 等长连接的响应会一直阻塞，
+		// 只能在调用者读取内容的同时记录，并在关闭时单独输出。
+		method, status := r.Method, resp.StatusCode
+		resp.Body = &teeBody{
+			body: resp.Body,
+			max:  t.maxBody,
+			done: func(data []byte) {
+				l.WithContext(ctx).
+					With("method", method).
+					With("url", u).
+					With("status", status).
+					With("resp_body", string(data)).
+					String("http client response body")
+			},
+		}
+	}
+
+	return resp, nil
+}
+
+func (t *transport) redactURL(u *url.URL) string {
+	if u.RawQuery == "" && u.User == nil {
+		return u.String()
+	}
+
+	uu := *u
+	uu.User = nil
+
+	if uu.RawQuery != "" { // 仅替换需要隐藏的值，保持其它内容及顺序不变。
+		params := strings.Split(uu.RawQuery, "&")
+		for i, param := range params {
+			k, _, _ := strings.Cut(param, "=")
+			if key, err := url.QueryUnescape(k); err == nil && t.isRedacted(key) {
+				params[i] = k + "=" + redactedValue
+			}
+		}
+		uu.RawQuery = strings.Join(params, "&")
+	}
+
+	return uu.String()
+}
+
+func (t *transport) isRedacted(key string) bool {
+	for _, name := range t.redact {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range redactHeaders {
+		if _, found := h[k]; found {
+			h[k] = []string{redactedValue}
+		}
+	}
+	return h
+}
+
+// 读取 body 中最多 size 字节的内容，并返回可以完整读取原始内容的 [io.ReadCloser]。
+func peekBody(body io.ReadCloser, size int) ([]byte, io.ReadCloser) {
+	data, err := io.ReadAll(io.LimitReader(body, int64(size)))
+	if err != nil && len(data) == 0 {
+		return nil, body
+	}
+	return data, &readCloser{Reader: io.MultiReader(bytes.NewReader(data), body), Closer: body}
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+
+	b.mux.Lock()
+	if b.done != nil && len(b.data) < b.max {
+		b.data = append(b.data, p[:min(n, b.max-len(b.data))]...)
+	}
+	b.mux.Unlock()
+
+	return n, err
+}
+
+func (b *teeBody) Close() error {
+	err := b.body.Close()
+
+	b.mux.Lock()
+	done, data := b.done, b.data
+	b.done = nil
+	b.mux.Unlock()
+
+	if done != nil { // 多次调用 Close 也只输出一次日志
+		done(data)
+	}
+	return err
+}
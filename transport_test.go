@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2014-2026 caixw
+//
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert/v4"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestNewTransport(t *testing.T) {
+	a := assert.New(t, false)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal(r.Header.Get(RequestIDHeader), "id1").
+			Equal(r.Header.Get("X-Propagate"), "v")
+
+		body, err := io.ReadAll(r.Body)
+		a.NotError(err)
+
+		switch r.URL.Path {
+		case "/404":
+			w.WriteHeader(http.StatusNotFound)
+		case "/500":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	buf := new(bytes.Buffer)
+	l := New(NewTextHandler(buf))
+	c := &http.Client{Transport: NewTransport(nil, l, &TransportOptions{
+		Headers:     true,
+		MaxBodySize: 3,
+		Propagators: []func(context.Context, http.Header){
+			func(_ context.Context, h http.Header) { h.Set("X-Propagate", "v") },
+		},
+	})}
+
+	ctx := context.WithValue(context.Background(), requestIDContextKey, "id1")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/path?token=abc&k=v&Token=a%2Bb&key2=%2A", strings.NewReader("12345"))
+	a.NotError(err)
+	req.Header.Set("Authorization", "secret")
+	resp, err := c.Do(req)
+	a.NotError(err).Equal(resp.StatusCode, http.StatusOK)
+	a.Empty(req.Header.Get(RequestIDHeader)) // 不会修改原始请求
+
+	// 请求的日志不需要等待 Body 关闭
+	a.Contains(buf.String(), "[INFO] http client").NotContains(buf.String(), "resp_body")
+	body, err := io.ReadAll(resp.Body)
+	a.NotError(err).Equal(string(body), "12345")
+	a.NotError(resp.Body.Close()).NotError(resp.Body.Close())
+	a.Equal(strings.Count(buf.String(), "http client response body"), 1)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	a.Length(lines, 2)
+	a.Contains(lines[0], "[INFO] http client").
+		Contains(lines[0], "method=POST").
+		Contains(lines[0], "/path?token=***&k=v&Token=***&key2=%2A").
+		Contains(lines[0], "req_body=123").
+		Contains(lines[0], "status=200").
+		Contains(lines[0], "latency=").
+		Contains(lines[0], "Authorization:[***]").
+		NotContains(buf.String(), "secret")
+	a.Contains(lines[1], "[INFO] http client response body").
+		Contains(lines[1], "method=POST").
+		Contains(lines[1], "status=200").
+		Contains(lines[1], "resp_body=123")
+
+	buf.Reset()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/404", nil)
+	a.NotError(err)
+	resp, err = c.Do(req)
+	a.NotError(err).Equal(resp.StatusCode, http.StatusNotFound)
+	a.Contains(buf.String(), "[WARN] http client").Contains(buf.String(), "status=404") // 未关闭 Body 也会输出
+	a.NotError(resp.Body.Close())
+
+	buf.Reset()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/500", nil)
+	a.NotError(err)
+	resp, err = c.Do(req)
+	a.NotError(err).Equal(resp.StatusCode, http.StatusInternalServerError)
+	a.Contains(buf.String(), "[ERRO] http client").Contains(buf.String(), "status=500")
+
+	// 出错
+	buf.Reset()
+	c = &http.Client{Transport: NewTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("transport error")
+	}), l, nil)}
+	_, err = c.Get("http://localhost/path")
+	a.Error(err)
+	a.Contains(buf.String(), "[ERRO] transport error").Contains(buf.String(), "url=http://localhost/path")
+
+	// 提升级别之后依然保留 AttrLogs 的属性
+	buf.Reset()
+	al := l.New(map[string]any{"app": "client"})
+	c = &http.Client{Transport: NewTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody}, nil
+	}), al, &TransportOptions{Level: LevelDebug})}
+	resp, err = c.Get("http://localhost/path")
+	a.NotError(err).Equal(resp.StatusCode, http.StatusBadGateway)
+	a.Contains(buf.String(), "[ERRO] http client").Contains(buf.String(), "app=client")
+}